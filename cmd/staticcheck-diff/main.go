@@ -0,0 +1,152 @@
+// staticcheck-diff compares the output of two staticcheck runs.
+package main // import "honnef.co/go/tools/cmd/staticcheck-diff"
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"honnef.co/go/tools/lint"
+	"honnef.co/go/tools/lint/lintutil"
+	"honnef.co/go/tools/lint/lintutil/format"
+	"honnef.co/go/tools/version"
+)
+
+var (
+	fFormat    string
+	fOldRoot   string
+	fNewRoot   string
+	fUnchanged bool
+	fVersion   bool
+)
+
+func init() {
+	flag.StringVar(&fFormat, "f", "text", "Output `format` (valid choices are 'text' and 'json')")
+	flag.StringVar(&fOldRoot, "old-root", "", "Directory the old run's file names are relative to")
+	flag.StringVar(&fNewRoot, "new-root", "", "Directory the new run's file names are relative to")
+	flag.BoolVar(&fUnchanged, "unchanged", false, "List unchanged problems in text output")
+	flag.BoolVar(&fVersion, "version", false, "Print version and exit")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\t%s [flags] old.json new.json\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nThe files contain the output of staticcheck -f json.\n")
+		fmt.Fprintf(os.Stderr, "The exit status is 1 if new problems were introduced.\n")
+		fmt.Fprintf(os.Stderr, "\nFlags:\n")
+		flag.PrintDefaults()
+	}
+}
+
+func load(path, root string) []lint.Problem {
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	ps, err := format.ReadJSON(f)
+	if err != nil {
+		log.Fatalf("%s: %s", path, err)
+	}
+	if root != "" {
+		// Make file names comparable between checkouts in different
+		// directories.
+		for i := range ps {
+			if rel, err := filepath.Rel(root, ps[i].Position.Filename); err == nil && !strings.HasPrefix(rel, "..") {
+				ps[i].Position.Filename = rel
+			}
+		}
+	}
+	return ps
+}
+
+func main() {
+	log.SetFlags(0)
+	flag.Parse()
+
+	if fVersion {
+		version.Print()
+		os.Exit(0)
+	}
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	c := lintutil.Compare(load(flag.Arg(0), fOldRoot), load(flag.Arg(1), fNewRoot))
+	switch fFormat {
+	case "text":
+		printText(c)
+	case "json":
+		printJSON(c)
+	default:
+		log.Fatalf("unsupported output format %q", fFormat)
+	}
+	if len(c.Introduced) > 0 {
+		os.Exit(1)
+	}
+}
+
+func printText(c lintutil.Comparison) {
+	var buf bytes.Buffer
+	text := format.Text{W: &buf}
+	list := func(prefix string, ps []lint.Problem) {
+		for _, p := range ps {
+			buf.WriteString(prefix)
+			text.Format(p)
+		}
+	}
+	list("+ ", c.Introduced)
+	list("- ", c.Fixed)
+	if fUnchanged {
+		list("  ", c.Unchanged)
+	}
+	if deltas := c.Deltas(); len(deltas) > 0 {
+		buf.WriteString("\n")
+		for _, d := range deltas {
+			fmt.Fprintf(&buf, "%-8s %4d -> %4d (%+d)\n", d.Check, d.Old, d.New, d.New-d.Old)
+		}
+	}
+	fmt.Fprintf(&buf, "\n%d introduced, %d fixed, %d unchanged\n",
+		len(c.Introduced), len(c.Fixed), len(c.Unchanged))
+	os.Stdout.Write(buf.Bytes())
+}
+
+func printJSON(c lintutil.Comparison) {
+	// Problems are encoded the same way as by staticcheck -f json.
+	encode := func(ps []lint.Problem) []json.RawMessage {
+		out := []json.RawMessage{}
+		for _, p := range ps {
+			var buf bytes.Buffer
+			format.JSON{W: &buf}.Format(p)
+			out = append(out, json.RawMessage(bytes.TrimSpace(buf.Bytes())))
+		}
+		return out
+	}
+	type delta struct {
+		Check string `json:"check"`
+		Old   int    `json:"old"`
+		New   int    `json:"new"`
+		Delta int    `json:"delta"`
+	}
+	out := struct {
+		Introduced []json.RawMessage `json:"introduced"`
+		Fixed      []json.RawMessage `json:"fixed"`
+		Unchanged  []json.RawMessage `json:"unchanged"`
+		Checks     []delta           `json:"checks"`
+	}{
+		Introduced: encode(c.Introduced),
+		Fixed:      encode(c.Fixed),
+		Unchanged:  encode(c.Unchanged),
+		Checks:     []delta{},
+	}
+	for _, d := range c.Deltas() {
+		out.Checks = append(out.Checks, delta{d.Check, d.Old, d.New, d.New - d.Old})
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "\t")
+	_ = enc.Encode(out)
+}
@@ -0,0 +1,55 @@
+// staticcheck-lsp makes staticcheck available to editors via the
+// Language Server Protocol.
+package main // import "honnef.co/go/tools/cmd/staticcheck-lsp"
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/lint/lintutil"
+	"honnef.co/go/tools/simple"
+	"honnef.co/go/tools/staticcheck"
+	"honnef.co/go/tools/stylecheck"
+	"honnef.co/go/tools/unused"
+	"honnef.co/go/tools/version"
+)
+
+func main() {
+	fs := lintutil.FlagSet("staticcheck-lsp")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\t%s [flags] # speaks LSP on stdin and stdout\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Flags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(os.Args[1:])
+
+	if fs.Lookup("version").Value.(flag.Getter).Get().(bool) {
+		version.Print()
+		os.Exit(0)
+	}
+
+	lintutil.Register(
+		simple.NewChecker(),
+		staticcheck.NewChecker(),
+		stylecheck.NewChecker(),
+		&unused.Checker{},
+	)
+
+	opts := lintutil.Options{
+		Config: config.Config{
+			Checks: fs.Lookup("checks").Value.(flag.Getter).Get().([]string),
+		},
+		Tags:          strings.Fields(fs.Lookup("tags").Value.(flag.Getter).Get().(string)),
+		LintTests:     fs.Lookup("tests").Value.(flag.Getter).Get().(bool),
+		GoVersion:     fs.Lookup("go").Value.(flag.Getter).Get().(int),
+		ReturnIgnored: fs.Lookup("show-ignored").Value.(flag.Getter).Get().(bool),
+	}
+	fail := fs.Lookup("fail").Value.(flag.Getter).Get().([]string)
+
+	s := newServer(os.Stdin, os.Stdout, lintutil.Checkers(), opts, fail)
+	os.Exit(s.run())
+}
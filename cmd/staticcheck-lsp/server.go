@@ -0,0 +1,494 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"honnef.co/go/tools/lint"
+	"honnef.co/go/tools/lint/lintutil"
+	"honnef.co/go/tools/version"
+)
+
+// changeDelay is how long we wait for further changes to a document
+// before linting it, so that we don't lint after every keystroke.
+const changeDelay = 500 * time.Millisecond
+
+type document struct {
+	text string
+	// timer, if not nil, lints the document once it hasn't changed
+	// for changeDelay.
+	timer *time.Timer
+}
+
+// entry ties a published diagnostic to the problem it was created
+// from.
+type entry struct {
+	diag    diagnostic
+	problem lint.Problem
+	// sources maps the names of files to their contents when they
+	// were linted, for converting the positions of edits.
+	sources map[string]string
+}
+
+type server struct {
+	conn     *conn
+	checkers []lint.Checker
+	opts     lintutil.Options
+	fail     []string
+
+	// lintMu serializes runs of the linter. Linting is expensive
+	// and runs in its own goroutine, so that we can keep serving
+	// requests in the meantime.
+	lintMu sync.Mutex
+
+	mu       sync.Mutex
+	docs     map[string]*document
+	entries  map[string][]entry
+	shutdown bool
+}
+
+func newServer(r io.Reader, w io.Writer, cs []lint.Checker, opts lintutil.Options, fail []string) *server {
+	return &server{
+		conn:     newConn(r, w),
+		checkers: cs,
+		opts:     opts,
+		fail:     fail,
+		docs:     map[string]*document{},
+		entries:  map[string][]entry{},
+	}
+}
+
+// run serves requests until the client sends the exit notification
+// or closes the connection. It returns the process' exit status.
+func (s *server) run() int {
+	for {
+		msg, err := s.conn.read()
+		if err != nil {
+			if rerr, ok := err.(*responseError); ok {
+				s.conn.reply(nil, nil, rerr)
+				continue
+			}
+			return 1
+		}
+		if msg.Method == "exit" {
+			s.mu.Lock()
+			shutdown := s.shutdown
+			s.mu.Unlock()
+			if shutdown {
+				return 0
+			}
+			return 1
+		}
+		if msg.ID == nil {
+			s.handleNotification(msg)
+			continue
+		}
+		res, err := s.handleRequest(msg)
+		s.conn.reply(msg.ID, res, err)
+	}
+}
+
+func (s *server) handleRequest(msg *message) (interface{}, error) {
+	switch msg.Method {
+	case "initialize":
+		var res initializeResult
+		res.Capabilities.TextDocumentSync.OpenClose = true
+		res.Capabilities.TextDocumentSync.Change = textDocumentSyncFull
+		res.Capabilities.HoverProvider = true
+		res.Capabilities.CodeActionProvider = true
+		res.ServerInfo.Name = "staticcheck-lsp"
+		res.ServerInfo.Version = version.Version
+		return res, nil
+	case "shutdown":
+		s.mu.Lock()
+		s.shutdown = true
+		s.mu.Unlock()
+		return nil, nil
+	case "textDocument/hover":
+		var params hoverParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.hover(params), nil
+	case "textDocument/codeAction":
+		var params codeActionParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.codeActions(params), nil
+	default:
+		return nil, &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method %q not found", msg.Method)}
+	}
+}
+
+func (s *server) handleNotification(msg *message) {
+	switch msg.Method {
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if unmarshalParams(msg, &params) != nil {
+			return
+		}
+		s.mu.Lock()
+		s.docs[params.TextDocument.URI] = &document{text: params.TextDocument.Text}
+		s.mu.Unlock()
+		go s.lint(params.TextDocument.URI)
+	case "textDocument/didChange":
+		var params didChangeParams
+		if unmarshalParams(msg, &params) != nil || len(params.ContentChanges) == 0 {
+			return
+		}
+		uri := params.TextDocument.URI
+		s.mu.Lock()
+		if doc, ok := s.docs[uri]; ok {
+			// We only support full document synchronisation, the
+			// last change contains the entire document.
+			doc.text = params.ContentChanges[len(params.ContentChanges)-1].Text
+			if doc.timer != nil {
+				doc.timer.Stop()
+			}
+			doc.timer = time.AfterFunc(changeDelay, func() { s.lint(uri) })
+		}
+		s.mu.Unlock()
+	case "textDocument/didSave":
+		var params didSaveParams
+		if unmarshalParams(msg, &params) != nil {
+			return
+		}
+		go s.lint(params.TextDocument.URI)
+	case "textDocument/didClose":
+		var params didCloseParams
+		if unmarshalParams(msg, &params) != nil {
+			return
+		}
+		uri := params.TextDocument.URI
+		s.mu.Lock()
+		if doc, ok := s.docs[uri]; ok && doc.timer != nil {
+			doc.timer.Stop()
+		}
+		delete(s.docs, uri)
+		delete(s.entries, uri)
+		s.mu.Unlock()
+		s.conn.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: uri, Diagnostics: []diagnostic{}})
+	}
+}
+
+func unmarshalParams(msg *message, v interface{}) error {
+	if err := json.Unmarshal(msg.Params, v); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+// lint lints the package containing the document identified by uri
+// and publishes diagnostics for all open documents in that package.
+func (s *server) lint(uri string) {
+	path, err := uriToPath(uri)
+	if err != nil {
+		return
+	}
+	dir := filepath.Dir(path)
+
+	s.lintMu.Lock()
+	defer s.lintMu.Unlock()
+
+	// Lint the contents of open documents, even if they haven't
+	// been saved yet. Positions of problems refer to the texts we
+	// linted, which may have changed by the time we're done.
+	opts := s.opts
+	opts.Overlay = map[string][]byte{}
+	texts := map[string]string{}
+	sources := map[string]string{}
+	s.mu.Lock()
+	for docURI, doc := range s.docs {
+		if docPath, err := uriToPath(docURI); err == nil {
+			opts.Overlay[docPath] = []byte(doc.text)
+			texts[docURI] = doc.text
+			sources[docPath] = doc.text
+		}
+	}
+	s.mu.Unlock()
+
+	ps, err := lintutil.Lint(s.checkers, []string{dir}, &opts)
+	if err != nil {
+		s.conn.notify("window/logMessage", map[string]interface{}{
+			"type":    severityError,
+			"message": fmt.Sprintf("couldn't lint %s: %s", dir, err),
+		})
+		return
+	}
+	var allChecks []string
+	for _, p := range ps {
+		allChecks = append(allChecks, p.Check)
+	}
+	fail := lint.FilterChecks(allChecks, s.fail)
+
+	// Fixes may edit files that aren't open.
+	for _, p := range ps {
+		for _, e := range p.Edits {
+			name := e.Position.Filename
+			if _, ok := sources[name]; ok {
+				continue
+			}
+			if b, err := ioutil.ReadFile(name); err == nil {
+				sources[name] = string(b)
+			}
+		}
+	}
+
+	byFile := map[string][]lint.Problem{}
+	for _, p := range ps {
+		if p.Severity == lint.Error && !fail[p.Check] && p.Check != "compile" {
+			p.Severity = lint.Warning
+		}
+		byFile[p.Position.Filename] = append(byFile[p.Position.Filename], p)
+	}
+
+	s.mu.Lock()
+	var publish []publishDiagnosticsParams
+	for docURI := range s.docs {
+		docPath, err := uriToPath(docURI)
+		text, ok := texts[docURI]
+		if err != nil || !ok || filepath.Dir(docPath) != dir {
+			continue
+		}
+		lines := strings.Split(text, "\n")
+		entries := make([]entry, 0, len(byFile[docPath]))
+		diags := make([]diagnostic, 0, len(byFile[docPath]))
+		for _, p := range byFile[docPath] {
+			d := problemToDiagnostic(p, lines)
+			entries = append(entries, entry{diag: d, problem: p, sources: sources})
+			diags = append(diags, d)
+		}
+		s.entries[docURI] = entries
+		publish = append(publish, publishDiagnosticsParams{URI: docURI, Diagnostics: diags})
+	}
+	s.mu.Unlock()
+
+	for _, params := range publish {
+		s.conn.notify("textDocument/publishDiagnostics", params)
+	}
+}
+
+func problemToDiagnostic(p lint.Problem, lines []string) diagnostic {
+	start := toPosition(p.Position, lines)
+	end := start
+	if p.End.IsValid() {
+		end = toPosition(p.End, lines)
+	}
+	d := diagnostic{
+		Range:   lspRange{Start: start, End: end},
+		Code:    p.Check,
+		Source:  "staticcheck",
+		Message: p.Text,
+	}
+	switch p.Severity {
+	case lint.Error:
+		d.Severity = severityError
+	case lint.Warning:
+		d.Severity = severityWarning
+	case lint.Ignored:
+		d.Severity = severityHint
+	}
+	return d
+}
+
+// toPosition converts a position with 1-based lines and byte columns
+// to an LSP position, which uses 0-based lines and UTF-16 columns.
+func toPosition(pos token.Position, lines []string) position {
+	if pos.Line < 1 {
+		return position{}
+	}
+	out := position{Line: pos.Line - 1}
+	if pos.Line > len(lines) || pos.Column < 1 {
+		return out
+	}
+	line := lines[pos.Line-1]
+	n := pos.Column - 1
+	if n > len(line) {
+		n = len(line)
+	}
+	for _, r := range line[:n] {
+		if r >= 0x10000 {
+			// Encoded as a surrogate pair in UTF-16.
+			out.Character += 2
+		} else {
+			out.Character++
+		}
+	}
+	return out
+}
+
+func (s *server) hover(params hoverParams) *hover {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var (
+		docs []string
+		seen = map[string]bool{}
+		rng  *lspRange
+	)
+	for _, e := range s.entries[params.TextDocument.URI] {
+		r := e.diag.Range
+		if r.Start == r.End {
+			// Editors display empty ranges as covering the rest
+			// of the line.
+			r.End = position{Line: r.Start.Line + 1}
+		}
+		if !r.contains(params.Position) || seen[e.problem.Check] {
+			continue
+		}
+		seen[e.problem.Check] = true
+		check, ok := findCheck(s.checkers, e.problem.Check)
+		if !ok || check.Doc == "" {
+			continue
+		}
+		docs = append(docs, fmt.Sprintf("%s: %s", check.ID, check.Doc))
+		if rng == nil {
+			rng = &e.diag.Range
+		}
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+	return &hover{
+		Contents: markupContent{Kind: "plaintext", Value: strings.Join(docs, "\n")},
+		Range:    rng,
+	}
+}
+
+func (s *server) codeActions(params codeActionParams) []codeAction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	uri := params.TextDocument.URI
+	doc, ok := s.docs[uri]
+	if !ok {
+		return nil
+	}
+	lines := strings.Split(doc.text, "\n")
+
+	var actions []codeAction
+	seen := map[string]bool{}
+	for _, e := range s.entries[uri] {
+		r := e.diag.Range
+		if r.Start.Line > params.Range.End.Line || r.End.Line < params.Range.Start.Line {
+			// Offer actions for all problems on the requested lines.
+			continue
+		}
+		if e.problem.Check == "" || e.problem.Check == "compile" {
+			continue
+		}
+		if changes, ok := fixChanges(uri, e); ok {
+			actions = append(actions, codeAction{
+				Title:       fmt.Sprintf("Fix %s: %s", e.problem.Check, e.problem.Text),
+				Kind:        "quickfix",
+				Diagnostics: []diagnostic{e.diag},
+				Edit:        workspaceEdit{Changes: changes},
+			})
+		}
+		line := r.Start.Line
+		key := fmt.Sprintf("%s:%d", e.problem.Check, line)
+		if seen[key] || line >= len(lines) {
+			continue
+		}
+		seen[key] = true
+
+		text := lines[line]
+		indent := text[:len(text)-len(strings.TrimLeft(text, " \t"))]
+		directive := fmt.Sprintf("%s//lint:ignore %s <reason>\n", indent, e.problem.Check)
+		actions = append(actions, codeAction{
+			Title:       fmt.Sprintf("Ignore %s on this line", e.problem.Check),
+			Kind:        "quickfix",
+			Diagnostics: []diagnostic{e.diag},
+			Edit: workspaceEdit{
+				Changes: map[string][]textEdit{
+					uri: {{
+						Range:   lspRange{Start: position{Line: line}, End: position{Line: line}},
+						NewText: directive,
+					}},
+				},
+			},
+		})
+	}
+	sort.SliceStable(actions, func(i, j int) bool {
+		return actions[i].Title < actions[j].Title
+	})
+	return actions
+}
+
+// fixChanges converts the edits of e's problem, which was reported in
+// the document uri, to changes to the documents they apply to. It
+// returns false if the problem has no edits or if we don't know the
+// linted contents of one of the files.
+func fixChanges(uri string, e entry) (map[string][]textEdit, bool) {
+	if len(e.problem.Edits) == 0 {
+		return nil, false
+	}
+	docPath, _ := uriToPath(uri)
+	changes := map[string][]textEdit{}
+	lines := map[string][]string{}
+	for _, edit := range e.problem.Edits {
+		name := edit.Position.Filename
+		l, ok := lines[name]
+		if !ok {
+			text, ok := e.sources[name]
+			if !ok {
+				return nil, false
+			}
+			l = strings.Split(text, "\n")
+			lines[name] = l
+		}
+		editURI := uri
+		if name != docPath {
+			editURI = pathToURI(name)
+		}
+		changes[editURI] = append(changes[editURI], textEdit{
+			Range: lspRange{
+				Start: toPosition(edit.Position, l),
+				End:   toPosition(edit.End, l),
+			},
+			NewText: edit.NewText,
+		})
+	}
+	return changes, true
+}
+
+func findCheck(cs []lint.Checker, id string) (lint.Check, bool) {
+	for _, c := range cs {
+		for _, check := range c.Checks() {
+			if check.ID == id {
+				return check, true
+			}
+		}
+	}
+	return lint.Check{}, false
+}
+
+func pathToURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		// Windows paths, such as C:/foo, become file:///C:/foo.
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported URI scheme %q", u.Scheme)
+	}
+	return filepath.FromSlash(u.Path), nil
+}
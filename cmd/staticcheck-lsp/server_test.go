@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/token"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"testing"
+
+	"honnef.co/go/tools/lint"
+	"honnef.co/go/tools/lint/lintutil"
+)
+
+func TestToPosition(t *testing.T) {
+	lines := []string{
+		"package a",
+		"var s = \"é\" + x",
+		"var t = \"😀\" + y",
+	}
+	tests := []struct {
+		line, column int
+		want         position
+	}{
+		{1, 1, position{0, 0}},
+		{1, 9, position{0, 8}},
+		// é is two bytes in UTF-8 and one code unit in UTF-16
+		{2, 16, position{1, 14}},
+		// 😀 is four bytes in UTF-8 and two code units in UTF-16
+		{3, 18, position{2, 15}},
+		// columns past the end of the line are clamped
+		{1, 100, position{0, 9}},
+		{4, 1, position{3, 0}},
+		{0, 1, position{0, 0}},
+	}
+	for _, tt := range tests {
+		got := toPosition(token.Position{Line: tt.line, Column: tt.column}, lines)
+		if got != tt.want {
+			t.Errorf("toPosition(%d:%d) = %v, want %v", tt.line, tt.column, got, tt.want)
+		}
+	}
+}
+
+func TestConnFraming(t *testing.T) {
+	var buf bytes.Buffer
+	c := newConn(nil, &buf)
+	if err := c.notify("window/logMessage", map[string]string{"message": "héllo"}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	i := strings.Index(out, "\r\n\r\n")
+	if i == -1 {
+		t.Fatalf("missing header terminator in %q", out)
+	}
+	body := out[i+4:]
+	if want := "Content-Length: " + strconv.Itoa(len(body)); out[:i] != want {
+		t.Errorf("got header %q, want %q", out[:i], want)
+	}
+
+	// Read back what we wrote, followed by a malformed message and
+	// one without a length.
+	in := out + "Content-Length: 3\r\n\r\n{x}" + "Content-Type: text/plain\r\n\r\n"
+	c = newConn(strings.NewReader(in), ioutil.Discard)
+	msg, err := c.read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var params map[string]string
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Method != "window/logMessage" || params["message"] != "héllo" {
+		t.Errorf("got %s %v", msg.Method, params)
+	}
+	if _, err := c.read(); err == nil {
+		t.Error("expected an error for malformed JSON")
+	} else if rerr, ok := err.(*responseError); !ok || rerr.Code != codeParseError {
+		t.Errorf("got error %v, want a parse error", err)
+	}
+	if _, err := c.read(); err == nil {
+		t.Error("expected an error for a missing Content-Length header")
+	}
+}
+
+type testChecker struct{}
+
+func (testChecker) Name() string       { return "test" }
+func (testChecker) Prefix() string     { return "TEST" }
+func (testChecker) Init(*lint.Program) {}
+func (testChecker) Checks() []lint.Check {
+	return []lint.Check{
+		{ID: "TEST1000", Doc: "Comparison to true"},
+		{ID: "TEST1001", Doc: "Unused variable"},
+	}
+}
+
+// testServer returns a server with one open document, /a/a.go, and
+// diagnostics for problems. sources holds the linted contents of
+// other files.
+func testServer(text string, sources map[string]string, ps []lint.Problem) (*server, string) {
+	const uri = "file:///a/a.go"
+	s := newServer(nil, ioutil.Discard, []lint.Checker{testChecker{}}, lintutil.Options{}, nil)
+	s.docs[uri] = &document{text: text}
+	all := map[string]string{"/a/a.go": text}
+	for name, src := range sources {
+		all[name] = src
+	}
+	lines := strings.Split(text, "\n")
+	for _, p := range ps {
+		s.entries[uri] = append(s.entries[uri], entry{diag: problemToDiagnostic(p, lines), problem: p, sources: all})
+	}
+	return s, uri
+}
+
+func TestCodeActionsAndHover(t *testing.T) {
+	const text = "package a\n\nfunc fn(b bool) {\n\tif b == true {\n\t}\n\tx := 1\n}\n"
+	pos := func(line, col int) token.Position {
+		return token.Position{Filename: "/a/a.go", Line: line, Column: col}
+	}
+	s, uri := testServer(text, nil, []lint.Problem{
+		{
+			Position: pos(4, 5),
+			End:      pos(4, 14),
+			Text:     "should omit comparison to true",
+			Check:    "TEST1000",
+			Edits:    []lint.TextEdit{{Position: pos(4, 5), End: pos(4, 14), NewText: "b"}},
+		},
+		{Position: pos(6, 2), Text: "x is unused", Check: "TEST1001"},
+	})
+
+	var params codeActionParams
+	params.TextDocument.URI = uri
+	params.Range = lspRange{Start: position{3, 0}, End: position{3, 0}}
+	actions := s.codeActions(params)
+	if len(actions) != 2 {
+		t.Fatalf("got %d code actions, want 2: %v", len(actions), actions)
+	}
+	fix, ignore := actions[0], actions[1]
+	wantFix := []textEdit{{Range: lspRange{position{3, 4}, position{3, 13}}, NewText: "b"}}
+	if b, _ := json.Marshal(fix.Edit.Changes[uri]); string(b) != mustMarshal(wantFix) {
+		t.Errorf("got fix %s, want %s", b, mustMarshal(wantFix))
+	}
+	wantIgnore := []textEdit{{Range: lspRange{position{3, 0}, position{3, 0}}, NewText: "\t//lint:ignore TEST1000 <reason>\n"}}
+	if b, _ := json.Marshal(ignore.Edit.Changes[uri]); string(b) != mustMarshal(wantIgnore) {
+		t.Errorf("got ignore directive %s, want %s", b, mustMarshal(wantIgnore))
+	}
+
+	tests := []struct {
+		pos  position
+		want string
+	}{
+		{position{3, 6}, "TEST1000: Comparison to true"},
+		// the diagnostic has an empty range, which covers the rest
+		// of the line
+		{position{5, 3}, "TEST1001: Unused variable"},
+		{position{2, 0}, ""},
+	}
+	for _, tt := range tests {
+		h := s.hover(hoverParams{TextDocument: textDocumentIdentifier{uri}, Position: tt.pos})
+		got := ""
+		if h != nil {
+			got = h.Contents.Value
+		}
+		if got != tt.want {
+			t.Errorf("hover at %v: got %q, want %q", tt.pos, got, tt.want)
+		}
+	}
+}
+
+func TestCodeActionsMultipleFiles(t *testing.T) {
+	const text = "package a\n\nvar x = 1\n"
+	pos := func(name string, line, col int) token.Position {
+		return token.Position{Filename: name, Line: line, Column: col}
+	}
+	problem := func(other string) lint.Problem {
+		return lint.Problem{
+			Position: pos("/a/a.go", 3, 9),
+			End:      pos("/a/a.go", 3, 10),
+			Text:     "rename",
+			Check:    "TEST1000",
+			Edits: []lint.TextEdit{
+				{Position: pos("/a/a.go", 3, 9), End: pos("/a/a.go", 3, 10), NewText: "2"},
+				{Position: pos(other, 2, 12), End: pos(other, 2, 13), NewText: "2"},
+			},
+		}
+	}
+	s, uri := testServer(text, map[string]string{"/a/b.go": "package a\n// é uses x\n"}, []lint.Problem{
+		problem("/a/b.go"),
+		// we don't know the contents of c.go, so there's no fix
+		problem("/a/c.go"),
+	})
+
+	var params codeActionParams
+	params.TextDocument.URI = uri
+	params.Range = lspRange{Start: position{2, 0}, End: position{2, 0}}
+	var fixes []codeAction
+	for _, a := range s.codeActions(params) {
+		if strings.HasPrefix(a.Title, "Fix") {
+			fixes = append(fixes, a)
+		}
+	}
+	if len(fixes) != 1 {
+		t.Fatalf("got %d fixes, want 1: %v", len(fixes), fixes)
+	}
+	want := map[string][]textEdit{
+		uri:              {{Range: lspRange{position{2, 8}, position{2, 9}}, NewText: "2"}},
+		"file:///a/b.go": {{Range: lspRange{position{1, 10}, position{1, 11}}, NewText: "2"}},
+	}
+	if got := mustMarshal(fixes[0].Edit.Changes); got != mustMarshal(want) {
+		t.Errorf("got changes %s, want %s", got, mustMarshal(want))
+	}
+}
+
+func mustMarshal(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return string(b)
+}
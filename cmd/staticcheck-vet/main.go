@@ -0,0 +1,185 @@
+// staticcheck-vet runs staticcheck as a go vet tool, checking one
+// package at a time:
+//
+//	go vet -vettool=$(which staticcheck-vet) ./...
+package main // import "honnef.co/go/tools/cmd/staticcheck-vet"
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"honnef.co/go/tools/lint"
+	"honnef.co/go/tools/lint/lintutil"
+	"honnef.co/go/tools/lint/lintutil/format"
+	"honnef.co/go/tools/simple"
+	"honnef.co/go/tools/staticcheck"
+	"honnef.co/go/tools/stylecheck"
+	"honnef.co/go/tools/unused"
+)
+
+// versionFlag implements -V=full, which go vet uses to compute the
+// tool's cache key.
+type versionFlag struct{}
+
+func (versionFlag) IsBoolFlag() bool { return true }
+func (versionFlag) Get() interface{} { return nil }
+func (versionFlag) String() string   { return "" }
+func (versionFlag) Set(s string) error {
+	if s != "full" {
+		log.Fatalf("unsupported flag value: -V=%s", s)
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		log.Fatal(err)
+	}
+	f, err := os.Open(exe)
+	if err != nil {
+		log.Fatal(err)
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		log.Fatal(err)
+	}
+	f.Close()
+	fmt.Printf("%s version devel comments-go-here buildID=%02x\n", filepath.Base(os.Args[0]), h.Sum(nil))
+	os.Exit(0)
+	return nil
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("staticcheck-vet: ")
+
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	fs.Var(versionFlag{}, "V", "Print version and exit")
+	printFlags := fs.Bool("flags", false, "Print flags as JSON and exit")
+	checks := fs.String("checks", "inherit", "Comma-separated list of `checks` to enable")
+	goVersion := fs.String("go", "", "Target Go `version` in the format '1.x'")
+	jsonOutput := fs.Bool("json", false, "Emit JSON output")
+	fs.Int("c", -1, "Display offending line with this many lines of context (ignored)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\tgo vet -vettool=$(which %s) [flags] packages\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "Flags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(os.Args[1:])
+
+	if *printFlags {
+		// go vet asks which flags it should pass on to us.
+		type jsonFlag struct {
+			Name  string
+			Bool  bool
+			Usage string
+		}
+		var flags []jsonFlag
+		fs.VisitAll(func(f *flag.Flag) {
+			if f.Name == "V" || f.Name == "flags" {
+				return
+			}
+			b, ok := f.Value.(interface{ IsBoolFlag() bool })
+			flags = append(flags, jsonFlag{f.Name, ok && b.IsBoolFlag(), f.Usage})
+		})
+		if err := json.NewEncoder(os.Stdout).Encode(flags); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}
+
+	if fs.NArg() != 1 || !strings.HasSuffix(fs.Arg(0), ".cfg") {
+		fs.Usage()
+		os.Exit(2)
+	}
+	cfg, err := lintutil.ReadVetConfig(fs.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opts := &lintutil.Options{GoVersion: -1}
+	opts.Config.Checks = strings.Split(*checks, ",")
+	if *goVersion != "" {
+		v, err := lint.ParseGoVersion(*goVersion)
+		if err != nil {
+			log.Fatalf("invalid Go version %q", *goVersion)
+		}
+		opts.GoVersion = v
+	}
+
+	lintutil.Register(
+		simple.NewChecker(),
+		staticcheck.NewChecker(),
+		stylecheck.NewChecker(),
+		&unused.Checker{},
+	)
+	ps, err := lintutil.Vet(lintutil.Checkers(), cfg, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *jsonOutput {
+		out := os.Stdout
+		if cfg.Stdout != "" {
+			// Newer versions of go vet collect our output in a file
+			out, err = os.Create(cfg.Stdout)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+		printJSON(out, cfg.ID, ps)
+		if err := out.Close(); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	// go vet prints our standard error and fails if we exit with a
+	// non-zero status.
+	f := format.Text{W: os.Stderr}
+	for _, p := range ps {
+		f.Format(p)
+	}
+	if len(ps) > 0 {
+		os.Exit(1)
+	}
+}
+
+// printJSON prints problems in the JSON format of the unitchecker
+// protocol, which maps package IDs and analyzer names to diagnostics.
+// Checks take the place of analyzers.
+func printJSON(w io.Writer, id string, ps []lint.Problem) {
+	type related struct {
+		Posn    string `json:"posn"`
+		Message string `json:"message"`
+	}
+	type diagnostic struct {
+		Posn    string    `json:"posn"`
+		Message string    `json:"message"`
+		Related []related `json:"related,omitempty"`
+	}
+	checks := map[string][]diagnostic{}
+	for _, p := range ps {
+		d := diagnostic{Posn: p.Position.String(), Message: p.Text}
+		for _, r := range p.Related {
+			d.Related = append(d.Related, related{r.Position.String(), r.Message})
+		}
+		name := p.Check
+		if name == "" {
+			name = "staticcheck"
+		}
+		checks[name] = append(checks[name], d)
+	}
+	if len(checks) == 0 {
+		return
+	}
+	tree := map[string]map[string][]diagnostic{id: checks}
+	b, err := json.MarshalIndent(tree, "", "\t")
+	if err != nil {
+		log.Fatal(err)
+	}
+	w.Write(append(b, '\n'))
+}
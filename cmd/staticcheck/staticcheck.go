@@ -4,7 +4,6 @@ package main // import "honnef.co/go/tools/cmd/staticcheck"
 import (
 	"os"
 
-	"honnef.co/go/tools/lint"
 	"honnef.co/go/tools/lint/lintutil"
 	"honnef.co/go/tools/simple"
 	"honnef.co/go/tools/staticcheck"
@@ -16,12 +15,12 @@ func main() {
 	fs := lintutil.FlagSet("staticcheck")
 	fs.Parse(os.Args[1:])
 
-	checkers := []lint.Checker{
+	lintutil.Register(
 		simple.NewChecker(),
 		staticcheck.NewChecker(),
 		stylecheck.NewChecker(),
 		&unused.Checker{},
-	}
+	)
 
-	lintutil.ProcessFlagSet(checkers, fs)
+	lintutil.ProcessFlagSet(lintutil.Checkers(), fs)
 }
@@ -1,8 +1,14 @@
 package config
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 )
@@ -56,21 +62,280 @@ func (cfg Config) Merge(ocfg Config) Config {
 	if ocfg.HTTPStatusCodeWhitelist != nil {
 		cfg.HTTPStatusCodeWhitelist = mergeLists(cfg.HTTPStatusCodeWhitelist, ocfg.HTTPStatusCodeWhitelist)
 	}
+	if ocfg.GeneratedFiles != nil {
+		cfg.GeneratedFiles = mergeLists(cfg.GeneratedFiles, ocfg.GeneratedFiles)
+	}
+	if ocfg.GeneratedHeaders != nil {
+		cfg.GeneratedHeaders = mergeLists(cfg.GeneratedHeaders, ocfg.GeneratedHeaders)
+	}
+	if ocfg.GeneratedCode != "" {
+		cfg.GeneratedCode = ocfg.GeneratedCode
+	}
+	if ocfg.GoVersion != "" {
+		cfg.GoVersion = ocfg.GoVersion
+	}
+	if ocfg.Fail != nil {
+		cfg.Fail = mergeLists(cfg.Fail, ocfg.Fail)
+	}
+	if ocfg.MaxErrors != nil {
+		cfg.MaxErrors = ocfg.MaxErrors
+	}
+	if ocfg.MaxWarnings != nil {
+		cfg.MaxWarnings = ocfg.MaxWarnings
+	}
+	if ocfg.FailChangedSince != "" {
+		cfg.FailChangedSince = ocfg.FailChangedSince
+	}
+	if ocfg.CallRules != nil {
+		cfg.CallRules = append(append([]CallRule(nil), cfg.CallRules...), ocfg.CallRules...)
+	}
+	if ocfg.BannedAPIs != nil {
+		cfg.BannedAPIs = append(append([]BannedAPI(nil), cfg.BannedAPIs...), ocfg.BannedAPIs...)
+	}
+	if ocfg.Deprecated != nil {
+		cfg.Deprecated = append(append([]Deprecation(nil), cfg.Deprecated...), ocfg.Deprecated...)
+	}
+	if ocfg.Checkers != nil {
+		checkers := make(map[string]map[string]interface{}, len(cfg.Checkers)+len(ocfg.Checkers))
+		for name, sec := range cfg.Checkers {
+			checkers[name] = sec
+		}
+		for name, osec := range ocfg.Checkers {
+			checkers[name] = mergeSections(cfg.Checkers[name], osec)
+		}
+		cfg.Checkers = checkers
+	}
 	return cfg
 }
 
+// mergeSections merges two checker sections. Options in b override
+// those in a, except for lists of strings, which may use "inherit" to
+// include the values of a.
+func mergeSections(a, b map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(a)+len(b))
+	for k, v := range a {
+		out[k] = v
+	}
+	for k, v := range b {
+		if bl, ok := stringList(v); ok {
+			al, _ := stringList(a[k])
+			l := mergeLists(al, bl)
+			vs := make([]interface{}, len(l))
+			for i, el := range l {
+				vs[i] = el
+			}
+			v = vs
+		}
+		out[k] = v
+	}
+	return out
+}
+
+func stringList(v interface{}) ([]string, bool) {
+	vs, ok := v.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	out := make([]string, len(vs))
+	for i, el := range vs {
+		s, ok := el.(string)
+		if !ok {
+			return nil, false
+		}
+		out[i] = s
+	}
+	return out, true
+}
+
+// Section decodes the options in the [checkers.<name>] section of
+// the configuration into v, which must be a pointer to a struct or
+// map. Fields of v that don't have corresponding options are left
+// untouched. This allows third-party checkers to be configured
+// alongside staticcheck's own checks.
+func (cfg Config) Section(name string, v interface{}) error {
+	sec, ok := cfg.Checkers[name]
+	if !ok {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(sec); err != nil {
+		return err
+	}
+	_, err := toml.DecodeReader(&buf, v)
+	return err
+}
+
+// Rules that can be used in call rules.
+const (
+	// CallRuleConstant requires the argument to be a constant.
+	CallRuleConstant = "constant"
+	// CallRuleNotNil requires the argument not to be the constant nil.
+	CallRuleNotNil = "not_nil"
+	// CallRuleRegexp requires constant strings to be valid regular
+	// expressions.
+	CallRuleRegexp = "regexp"
+	// CallRuleURL requires constant strings to be valid URLs.
+	CallRuleURL = "url"
+	// CallRuleTimeLayout requires constant strings to be valid time
+	// layouts.
+	CallRuleTimeLayout = "time_layout"
+	// CallRuleHostPort requires constant strings to be valid
+	// host:port pairs.
+	CallRuleHostPort = "host_port"
+)
+
+// A CallRule constrains an argument of all calls of a function.
+type CallRule struct {
+	// Function is the fully qualified name of the function, such as
+	// "example.com/log.Fields" or "(*example.com/log.Logger).Print".
+	Function string `toml:"function"`
+	// Argument is the index of the argument, starting at 0 and not
+	// counting the receiver. If it is the index of a variadic
+	// parameter, the rule applies to each of the variadic arguments.
+	Argument int `toml:"argument"`
+	// Rule is one of the CallRule constants.
+	Rule string `toml:"rule"`
+	// Message, if not empty, replaces the default message of
+	// violations of the rule.
+	Message string `toml:"message"`
+}
+
+// A BannedAPI forbids the use of a package, function, method, type or
+// variable.
+type BannedAPI struct {
+	// Name is either the import path of a package, whose imports
+	// are banned, or the fully qualified name of an object, such as
+	// "net/http.DefaultClient" or "(*net/http.Client).Do".
+	Name string `toml:"name"`
+	// Message, if not empty, is appended to reports of uses, for
+	// example to name a replacement.
+	Message string `toml:"message"`
+	// Allow lists directories in which the API may be used. Relative
+	// directories are resolved relative to the configuration file
+	// that bans the API. A trailing "/..." includes all
+	// subdirectories.
+	Allow []string `toml:"allow"`
+}
+
+// Allowed reports whether the banned API may be used by code in dir.
+func (b BannedAPI) Allowed(dir string) bool {
+	for _, a := range b.Allow {
+		if strings.HasSuffix(a, "/...") {
+			a = filepath.Clean(strings.TrimSuffix(a, "/..."))
+			if dir == a || strings.HasPrefix(dir, a+string(filepath.Separator)) {
+				return true
+			}
+		} else if dir == filepath.Clean(a) {
+			return true
+		}
+	}
+	return false
+}
+
+// A Deprecation marks a package or object as deprecated, in addition
+// to those whose documentation contains a "Deprecated: " paragraph.
+type Deprecation struct {
+	// Name is either the import path of a package or the fully
+	// qualified name of an object, as in BannedAPI.
+	Name string `toml:"name"`
+	// Alternative describes what to use instead.
+	Alternative string `toml:"alternative"`
+	// Since, if not empty, is the date, in the format YYYY-MM-DD,
+	// from which on uses are reported.
+	Since string `toml:"since"`
+}
+
+const dateLayout = "2006-01-02"
+
+// Effective reports whether the deprecation is in effect at time t.
+func (d Deprecation) Effective(t time.Time) bool {
+	if d.Since == "" {
+		return true
+	}
+	since, err := time.ParseInLocation(dateLayout, d.Since, t.Location())
+	if err != nil {
+		// Load has validated the date.
+		return true
+	}
+	return !t.Before(since)
+}
+
+const (
+	// GeneratedCodeFilter only filters problems in generated files
+	// for checks that ask for it.
+	GeneratedCodeFilter = "filter"
+	// GeneratedCodeExclude filters all problems in generated files.
+	GeneratedCodeExclude = "exclude"
+)
+
 type Config struct {
-	// TODO(dh): this implementation makes it impossible for external
-	// clients to add their own checkers with configuration. At the
-	// moment, we don't really care about that; we don't encourage
-	// that people use this package. In the future, we may. The
-	// obvious solution would be using map[string]interface{}, but
-	// that's obviously subpar.
+	// The options of third-party checkers live in the Checkers
+	// field, the options below are for staticcheck's own checks.
 
 	Checks                  []string `toml:"checks"`
 	Initialisms             []string `toml:"initialisms"`
 	DotImportWhitelist      []string `toml:"dot_import_whitelist"`
 	HTTPStatusCodeWhitelist []string `toml:"http_status_code_whitelist"`
+
+	// GeneratedFiles lists glob patterns of file names that are
+	// considered generated, in addition to files with the standard
+	// "Code generated ... DO NOT EDIT." header. The default patterns
+	// match the files of common generators, such as protoc-gen-go,
+	// stringer, mockgen and goyacc.
+	GeneratedFiles []string `toml:"generated_files"`
+	// GeneratedHeaders lists regular expressions that, when matching
+	// a line of a file, mark the file as generated.
+	GeneratedHeaders []string `toml:"generated_headers"`
+	// GeneratedCode is either GeneratedCodeFilter or
+	// GeneratedCodeExclude.
+	GeneratedCode string `toml:"generated_code"`
+
+	// GoVersion is the targeted version of Go, in the format 1.x.
+	// If it is empty, the version declared in the module's go.mod
+	// file is used.
+	GoVersion string `toml:"go_version"`
+
+	// The following options make up the policy that determines the
+	// exit status. They are taken from the configuration of the
+	// directory that the linter runs in.
+
+	// Fail lists the checks that can cause a non-zero exit status;
+	// problems in other checks are reported as warnings.
+	Fail []string `toml:"fail"`
+	// MaxErrors is the number of errors that are tolerated. A
+	// negative value means that any number is tolerated.
+	MaxErrors *int `toml:"max_errors"`
+	// MaxWarnings is the number of warnings that are tolerated. A
+	// negative value means that any number is tolerated.
+	MaxWarnings *int `toml:"max_warnings"`
+	// FailChangedSince, if not empty, is a git revision. Only
+	// problems in files that changed since that revision count
+	// towards the exit status.
+	FailChangedSince string `toml:"fail_changed_since"`
+
+	// CallRules constrain the arguments of calls. Rules are
+	// inherited from parent directories; configuration files add to
+	// them.
+	CallRules []CallRule `toml:"call_rules"`
+
+	// BannedAPIs lists packages and objects that may not be used.
+	// Bans are inherited from parent directories; configuration
+	// files add to them.
+	BannedAPIs []BannedAPI `toml:"banned_apis"`
+
+	// Deprecated lists additional deprecated packages and objects.
+	// Deprecations are inherited from parent directories; when
+	// several configuration files deprecate the same name, the one
+	// closest to the package wins.
+	Deprecated []Deprecation `toml:"deprecated"`
+
+	// Checkers maps checker names to the options of third-party
+	// checkers. Use Section to decode them.
+	Checkers map[string]map[string]interface{} `toml:"checkers"`
+
+	// Root stops the inheritance of configuration from parent
+	// directories. It is not itself inherited.
+	Root bool `toml:"root"`
 }
 
 var defaultConfig = Config{
@@ -86,36 +351,60 @@ var defaultConfig = Config{
 	},
 	DotImportWhitelist:      []string{},
 	HTTPStatusCodeWhitelist: []string{"200", "400", "404", "500"},
+	GeneratedFiles: []string{
+		// protoc-gen-go and grpc-gateway
+		"*.pb.go", "*.pb.gw.go",
+		// stringer
+		"*_string.go",
+		// mockgen
+		"mock_*.go", "*_mock.go",
+		// goyacc
+		"y.go",
+	},
+	GeneratedHeaders: []string{},
+	GeneratedCode:    GeneratedCodeFilter,
+	Fail:             []string{"all"},
+	MaxErrors:        intPtr(0),
+	MaxWarnings:      intPtr(-1),
 }
 
+func intPtr(i int) *int { return &i }
+
 const configName = "staticcheck.conf"
 
-func parseConfigs(dir string) ([]Config, error) {
+func parseConfigs(dir string, rootMarkers []string) ([]Config, error) {
 	var out []Config
 
-	// TODO(dh): consider stopping at the GOPATH/module boundary
 	for dir != "" {
 		f, err := os.Open(filepath.Join(dir, configName))
-		if os.IsNotExist(err) {
-			ndir := filepath.Dir(dir)
-			if ndir == dir {
-				break
-			}
-			dir = ndir
-			continue
-		}
-		if err != nil {
+		if err != nil && !os.IsNotExist(err) {
 			return nil, err
 		}
-		var cfg Config
-		_, err = toml.DecodeReader(f, &cfg)
-		f.Close()
-		if err != nil {
-			return nil, err
+		root := isRoot(dir, rootMarkers)
+		if err == nil {
+			var cfg Config
+			_, err = toml.DecodeReader(f, &cfg)
+			f.Close()
+			if err != nil {
+				return nil, err
+			}
+			for i, b := range cfg.BannedAPIs {
+				for j, a := range b.Allow {
+					if !filepath.IsAbs(a) {
+						suffix := ""
+						if strings.HasSuffix(a, "/...") {
+							a, suffix = strings.TrimSuffix(a, "/..."), "/..."
+						}
+						b.Allow[j] = filepath.Join(dir, a) + suffix
+					}
+				}
+				cfg.BannedAPIs[i] = b
+			}
+			out = append(out, cfg)
+			root = root || cfg.Root
 		}
-		out = append(out, cfg)
 		ndir := filepath.Dir(dir)
-		if ndir == dir {
+		if root || ndir == dir {
 			break
 		}
 		dir = ndir
@@ -130,6 +419,17 @@ func parseConfigs(dir string) ([]Config, error) {
 	return out, nil
 }
 
+// isRoot reports whether dir contains any of the named files or
+// directories.
+func isRoot(dir string, markers []string) bool {
+	for _, m := range markers {
+		if _, err := os.Stat(filepath.Join(dir, m)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
 func mergeConfigs(confs []Config) Config {
 	if len(confs) == 0 {
 		// This shouldn't happen because we always have at least a
@@ -146,8 +446,14 @@ func mergeConfigs(confs []Config) Config {
 	return conf
 }
 
-func Load(dir string) (Config, error) {
-	confs, err := parseConfigs(dir)
+// Load returns the configuration for dir, which is the result of
+// merging the default configuration and the configuration files in
+// dir and its parent directories. Parent directories are only
+// searched until a configuration file sets root = true, or until a
+// directory containing any of the files named by rootMarkers, such as
+// go.mod or .git, has been processed.
+func Load(dir string, rootMarkers ...string) (Config, error) {
+	confs, err := parseConfigs(dir, rootMarkers)
 	if err != nil {
 		return Config{}, err
 	}
@@ -157,6 +463,64 @@ func Load(dir string) (Config, error) {
 	conf.Initialisms = normalizeList(conf.Initialisms)
 	conf.DotImportWhitelist = normalizeList(conf.DotImportWhitelist)
 	conf.HTTPStatusCodeWhitelist = normalizeList(conf.HTTPStatusCodeWhitelist)
+	conf.GeneratedFiles = normalizeList(conf.GeneratedFiles)
+	conf.GeneratedHeaders = normalizeList(conf.GeneratedHeaders)
+	conf.Fail = normalizeList(conf.Fail)
 
+	if err := conf.validate(); err != nil {
+		return Config{}, err
+	}
 	return conf, nil
 }
+
+var goVersionRe = regexp.MustCompile(`^1\.\d+$`)
+
+func (cfg Config) validate() error {
+	for _, pattern := range cfg.GeneratedFiles {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid generated_files pattern %q: %s", pattern, err)
+		}
+	}
+	for _, h := range cfg.GeneratedHeaders {
+		if _, err := regexp.Compile(h); err != nil {
+			return fmt.Errorf("invalid generated_headers pattern %q: %s", h, err)
+		}
+	}
+	if cfg.GoVersion != "" && !goVersionRe.MatchString(cfg.GoVersion) {
+		return fmt.Errorf("invalid go_version %q, must be of the form 1.x", cfg.GoVersion)
+	}
+	for _, r := range cfg.CallRules {
+		if r.Function == "" {
+			return fmt.Errorf("call rule %q is missing a function", r.Rule)
+		}
+		if r.Argument < 0 {
+			return fmt.Errorf("invalid argument %d in call rule for %s", r.Argument, r.Function)
+		}
+		switch r.Rule {
+		case CallRuleConstant, CallRuleNotNil, CallRuleRegexp, CallRuleURL, CallRuleTimeLayout, CallRuleHostPort:
+		default:
+			return fmt.Errorf("unknown rule %q in call rule for %s", r.Rule, r.Function)
+		}
+	}
+	for _, b := range cfg.BannedAPIs {
+		if b.Name == "" {
+			return errors.New("banned API is missing a name")
+		}
+	}
+	for _, d := range cfg.Deprecated {
+		if d.Name == "" {
+			return errors.New("deprecation is missing a name")
+		}
+		if d.Since != "" {
+			if _, err := time.Parse(dateLayout, d.Since); err != nil {
+				return fmt.Errorf("invalid date %q in deprecation of %s, must be of the form YYYY-MM-DD", d.Since, d.Name)
+			}
+		}
+	}
+	switch cfg.GeneratedCode {
+	case GeneratedCodeFilter, GeneratedCodeExclude:
+	default:
+		return fmt.Errorf("invalid value %q for generated_code", cfg.GeneratedCode)
+	}
+	return nil
+}
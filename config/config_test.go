@@ -0,0 +1,97 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSection(t *testing.T) {
+	parent := Config{Checkers: map[string]map[string]interface{}{
+		"mycorp": {
+			"banned": []interface{}{"io/ioutil"},
+			"level":  int64(1),
+		},
+	}}
+	child := Config{Checkers: map[string]map[string]interface{}{
+		"mycorp": {
+			"banned": []interface{}{"inherit", "net/http.DefaultClient"},
+		},
+	}}
+	cfg := parent.Merge(child)
+
+	var opts struct {
+		Banned []string `toml:"banned"`
+		Level  int      `toml:"level"`
+		Other  string   `toml:"other"`
+	}
+	opts.Other = "default"
+	if err := cfg.Section("mycorp", &opts); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"io/ioutil", "net/http.DefaultClient"}; !reflect.DeepEqual(opts.Banned, want) {
+		t.Errorf("got banned = %q, want %q", opts.Banned, want)
+	}
+	if opts.Level != 1 {
+		t.Errorf("got level = %d, want 1", opts.Level)
+	}
+	if opts.Other != "default" {
+		t.Errorf("unset option was overwritten: %q", opts.Other)
+	}
+}
+
+func TestCallRules(t *testing.T) {
+	parent := Config{CallRules: []CallRule{{Function: "example.com/log.Fields", Rule: CallRuleConstant}}}
+	child := Config{CallRules: []CallRule{{Function: "example.com/log.Dial", Rule: CallRuleHostPort}}}
+	cfg := defaultConfig.Merge(parent).Merge(child)
+	if len(cfg.CallRules) != 2 {
+		t.Fatalf("got %d call rules, want 2", len(cfg.CallRules))
+	}
+	if err := cfg.validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.CallRules = append(cfg.CallRules, CallRule{Function: "example.com/log.Fields", Rule: "positive"})
+	if err := cfg.validate(); err == nil {
+		t.Error("unknown rule was accepted")
+	}
+}
+
+func TestBannedAPIAllowed(t *testing.T) {
+	b := BannedAPI{Allow: []string{"/src/cmd/...", "/src/internal"}}
+	tests := []struct {
+		dir  string
+		want bool
+	}{
+		{"/src/cmd", true},
+		{"/src/cmd/tool", true},
+		{"/src/cmdline", false},
+		{"/src/internal", true},
+		{"/src/internal/sub", false},
+		{"/src", false},
+	}
+	for _, tt := range tests {
+		if got := b.Allowed(tt.dir); got != tt.want {
+			t.Errorf("Allowed(%q) = %t, want %t", tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestDeprecationEffective(t *testing.T) {
+	d := Deprecation{Name: "example.com/old", Since: "2019-06-01"}
+	if d.Effective(time.Date(2019, 5, 31, 23, 0, 0, 0, time.UTC)) {
+		t.Error("deprecation is in effect before its date")
+	}
+	if !d.Effective(time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Error("deprecation isn't in effect on its date")
+	}
+	cfg := defaultConfig
+	cfg.Deprecated = []Deprecation{d}
+	if err := cfg.validate(); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Deprecated = []Deprecation{{Name: "example.com/old", Since: "June"}}
+	if err := cfg.validate(); err == nil {
+		t.Error("invalid date was accepted")
+	}
+}
@@ -0,0 +1,135 @@
+// Package deprecated contains information about deprecated APIs in
+// the standard library and in modules.
+package deprecated // import "honnef.co/go/tools/deprecated"
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+//go:generate go run gen.go
+
+// A Deprecation describes when an object was deprecated, and since
+// when an alternative to it has been available. Versions are minor
+// versions of Go 1.
+type Deprecation struct {
+	DeprecatedSince           int
+	AlternativeAvailableSince int
+}
+
+// Paragraph returns the text of the paragraph of the documentation
+// doc that starts with "Deprecated:", joined into a single line. The
+// text may start on the line following "Deprecated:".
+func Paragraph(doc string) (string, bool) {
+	for _, para := range strings.Split(doc, "\n\n") {
+		para = strings.TrimSpace(para)
+		if !strings.HasPrefix(para, "Deprecated:") {
+			continue
+		}
+		alt := strings.TrimSpace(para[len("Deprecated:"):])
+		return strings.Join(strings.Fields(alt), " "), true
+	}
+	return "", false
+}
+
+// Package returns the objects of the package in dir, whose import
+// path is path, that are documented as deprecated. The objects' names
+// have the format used by Stdlib and are mapped to the deprecation
+// messages.
+func Package(dir, path string) (map[string]string, error) {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]string{}
+	fset := token.NewFileSet()
+	add := func(name string, docs ...*ast.CommentGroup) {
+		if !ast.IsExported(name[strings.LastIndex(name, ".")+1:]) {
+			return
+		}
+		for _, doc := range docs {
+			if doc == nil {
+				continue
+			}
+			if alt, ok := Paragraph(doc.Text()); ok {
+				out[name] = alt
+				return
+			}
+		}
+	}
+	for _, fi := range fis {
+		if fi.IsDir() || !strings.HasSuffix(fi.Name(), ".go") || strings.HasSuffix(fi.Name(), "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, fi.Name()), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		if f.Name.Name == "main" || f.Name.Name == "documentation" {
+			// generators and other files excluded by build tags
+			continue
+		}
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					add(path+"."+decl.Name.Name, decl.Doc)
+					continue
+				}
+				recv, star := receiverName(decl.Recv.List[0].Type)
+				if recv == "" || !ast.IsExported(recv) {
+					continue
+				}
+				add("("+star+path+"."+recv+")."+decl.Name.Name, decl.Doc)
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						if !ast.IsExported(spec.Name.Name) {
+							continue
+						}
+						add(path+"."+spec.Name.Name, spec.Doc, decl.Doc)
+						prefix := "(" + path + "." + spec.Name.Name + ")."
+						switch typ := spec.Type.(type) {
+						case *ast.StructType:
+							for _, field := range typ.Fields.List {
+								for _, name := range field.Names {
+									add(prefix+name.Name, field.Doc)
+								}
+							}
+						case *ast.InterfaceType:
+							for _, field := range typ.Methods.List {
+								for _, name := range field.Names {
+									add(prefix+name.Name, field.Doc)
+								}
+							}
+						}
+					case *ast.ValueSpec:
+						for _, name := range spec.Names {
+							add(path+"."+name.Name, spec.Doc, decl.Doc)
+						}
+					}
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+func receiverName(expr ast.Expr) (name, star string) {
+	if s, ok := expr.(*ast.StarExpr); ok {
+		expr, star = s.X, "*"
+	}
+	if e, ok := expr.(*ast.IndexExpr); ok {
+		// a generic type with a single type parameter
+		expr = e.X
+	}
+	if id, ok := expr.(*ast.Ident); ok {
+		return id.Name, star
+	}
+	return "", ""
+}
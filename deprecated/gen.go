@@ -0,0 +1,31 @@
+// +build ignore
+
+// gen.go generates stdlib.go from the standard library of a GOROOT.
+//
+// Usage:
+//
+//	go run gen.go [-goroot dir]
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"log"
+	"runtime"
+
+	"honnef.co/go/tools/deprecated/internal/gen"
+)
+
+func main() {
+	goroot := flag.String("goroot", runtime.GOROOT(), "GOROOT to read the standard library from")
+	out := flag.String("o", "stdlib.go", "output file")
+	flag.Parse()
+
+	src, err := gen.Generate(*goroot)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,343 @@
+// Package gen generates the table of deprecated objects of the
+// standard library.
+package gen // import "honnef.co/go/tools/deprecated/internal/gen"
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"honnef.co/go/tools/deprecated"
+)
+
+// An override holds the DeprecatedSince and AlternativeAvailableSince
+// of a deprecation.
+type override [2]int
+
+// overrides are hand-verified deprecations that take precedence over
+// what the api files say. Go 1.16 marked all older deprecations in
+// its api file at once, so the api files can't tell when objects
+// deprecated before Go 1.16 were deprecated, nor can they tell since
+// when alternatives have been available.
+var overrides = map[string]override{
+	"image/jpeg.Reader":                           {4, 0},
+	"go/build.AllowBinary":                        {7, 7},
+	"(archive/zip.FileHeader).CompressedSize":     {1, 1},
+	"(archive/zip.FileHeader).UncompressedSize":   {1, 1},
+	"(archive/zip.FileHeader).ModifiedTime":       {10, 10},
+	"(archive/zip.FileHeader).ModifiedDate":       {10, 10},
+	"(*archive/zip.FileHeader).ModTime":           {10, 10},
+	"(*archive/zip.FileHeader).SetModTime":        {10, 10},
+	"(go/doc.Package).Bugs":                       {1, 1},
+	"os.SEEK_SET":                                 {7, 7},
+	"os.SEEK_CUR":                                 {7, 7},
+	"os.SEEK_END":                                 {7, 7},
+	"(net.Dialer).Cancel":                         {7, 7},
+	"runtime.CPUProfile":                          {9, 0},
+	"compress/flate.ReadError":                    {6, 6},
+	"compress/flate.WriteError":                   {6, 6},
+	"path/filepath.HasPrefix":                     {0, 0},
+	"(net/http.Transport).Dial":                   {7, 7},
+	"(*net/http.Transport).CancelRequest":         {6, 5},
+	"net/http.ErrWriteAfterFlush":                 {7, 0},
+	"net/http.ErrHeaderTooLong":                   {8, 0},
+	"net/http.ErrShortBody":                       {8, 0},
+	"net/http.ErrMissingContentLength":            {8, 0},
+	"net/http/httputil.ErrPersistEOF":             {0, 0},
+	"net/http/httputil.ErrClosed":                 {0, 0},
+	"net/http/httputil.ErrPipeline":               {0, 0},
+	"net/http/httputil.ServerConn":                {0, 0},
+	"net/http/httputil.NewServerConn":             {0, 0},
+	"net/http/httputil.ClientConn":                {0, 0},
+	"net/http/httputil.NewClientConn":             {0, 0},
+	"net/http/httputil.NewProxyClientConn":        {0, 0},
+	"(net/http.Request).Cancel":                   {7, 7},
+	"(text/template/parse.PipeNode).Line":         {1, 1},
+	"(text/template/parse.ActionNode).Line":       {1, 1},
+	"(text/template/parse.BranchNode).Line":       {1, 1},
+	"(text/template/parse.TemplateNode).Line":     {1, 1},
+	"database/sql/driver.ColumnConverter":         {9, 9},
+	"database/sql/driver.Execer":                  {8, 8},
+	"database/sql/driver.Queryer":                 {8, 8},
+	"(database/sql/driver.Conn).Begin":            {8, 8},
+	"(database/sql/driver.Stmt).Exec":             {8, 8},
+	"(database/sql/driver.Stmt).Query":            {8, 8},
+	"syscall.StringByteSlice":                     {1, 1},
+	"syscall.StringBytePtr":                       {1, 1},
+	"syscall.StringSlicePtr":                      {1, 1},
+	"syscall.StringToUTF16":                       {1, 1},
+	"syscall.StringToUTF16Ptr":                    {1, 1},
+	"(*regexp.Regexp).Copy":                       {12, 12},
+	"(archive/tar.Header).Xattrs":                 {10, 10},
+	"archive/tar.TypeRegA":                        {11, 1},
+	"go/types.NewInterface":                       {11, 11},
+	"(*go/types.Interface).Embedded":              {11, 11},
+	"go/importer.For":                             {12, 12},
+	"encoding/json.InvalidUTF8Error":              {2, 2},
+	"encoding/json.UnmarshalFieldError":           {2, 2},
+	"encoding/csv.ErrTrailingComma":               {2, 2},
+	"(encoding/csv.Reader).TrailingComma":         {2, 2},
+	"(net.Dialer).DualStack":                      {12, 12},
+	"net/http.ErrUnexpectedTrailer":               {12, 12},
+	"net/http.CloseNotifier":                      {11, 7},
+	"net/http.ProtocolError":                      {8, 8},
+	"(crypto/x509.CertificateRequest).Attributes": {5, 3},
+	// This function has no alternative, but also no purpose.
+	"(*crypto/rc4.Cipher).Reset":                     {12, 0},
+	"(net/http/httptest.ResponseRecorder).HeaderMap": {11, 7},
+
+	// All of these have been deprecated in favour of external libraries
+	"syscall.AttachLsf":                     {7, 0},
+	"syscall.DetachLsf":                     {7, 0},
+	"syscall.LsfSocket":                     {7, 0},
+	"syscall.SetLsfPromisc":                 {7, 0},
+	"syscall.LsfJump":                       {7, 0},
+	"syscall.LsfStmt":                       {7, 0},
+	"syscall.BpfStmt":                       {7, 0},
+	"syscall.BpfJump":                       {7, 0},
+	"syscall.BpfBuflen":                     {7, 0},
+	"syscall.SetBpfBuflen":                  {7, 0},
+	"syscall.BpfDatalink":                   {7, 0},
+	"syscall.SetBpfDatalink":                {7, 0},
+	"syscall.SetBpfPromisc":                 {7, 0},
+	"syscall.FlushBpf":                      {7, 0},
+	"syscall.BpfInterface":                  {7, 0},
+	"syscall.SetBpfInterface":               {7, 0},
+	"syscall.BpfTimeout":                    {7, 0},
+	"syscall.SetBpfTimeout":                 {7, 0},
+	"syscall.BpfStats":                      {7, 0},
+	"syscall.SetBpfImmediate":               {7, 0},
+	"syscall.SetBpf":                        {7, 0},
+	"syscall.CheckBpfVersion":               {7, 0},
+	"syscall.BpfHeadercmpl":                 {7, 0},
+	"syscall.SetBpfHeadercmpl":              {7, 0},
+	"syscall.RouteRIB":                      {8, 0},
+	"syscall.RoutingMessage":                {8, 0},
+	"syscall.RouteMessage":                  {8, 0},
+	"syscall.InterfaceMessage":              {8, 0},
+	"syscall.InterfaceAddrMessage":          {8, 0},
+	"syscall.ParseRoutingMessage":           {8, 0},
+	"syscall.ParseRoutingSockaddr":          {8, 0},
+	"syscall.InterfaceAnnounceMessage":      {7, 0},
+	"syscall.InterfaceMulticastAddrMessage": {7, 0},
+	"syscall.FormatMessage":                 {5, 0},
+}
+
+// apiSymbol describes an exported symbol of the standard library, as
+// recorded by the api/go1.N.txt files of a GOROOT.
+type apiSymbol struct {
+	added      int
+	deprecated int // -1 if the api files don't mark it as deprecated
+}
+
+var (
+	apiFileRe  = regexp.MustCompile(`^go1(?:\.(\d+))?\.txt$`)
+	apiIssueRe = regexp.MustCompile(` #\d+$`)
+)
+
+// readAPI parses the api files of goroot. It returns the symbols,
+// keyed by their names in the format used by Stdlib, and the newest
+// Go version that has an api file.
+func readAPI(goroot string) (map[string]*apiSymbol, []string, int, error) {
+	fis, err := ioutil.ReadDir(filepath.Join(goroot, "api"))
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	type apiFile struct {
+		name    string
+		version int
+	}
+	var files []apiFile
+	for _, fi := range fis {
+		m := apiFileRe.FindStringSubmatch(fi.Name())
+		if m == nil {
+			continue
+		}
+		v := 0
+		if m[1] != "" {
+			v, _ = strconv.Atoi(m[1])
+		}
+		files = append(files, apiFile{fi.Name(), v})
+	}
+	if len(files) == 0 {
+		return nil, nil, 0, fmt.Errorf("no api files in %s", goroot)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].version < files[j].version })
+
+	syms := map[string]*apiSymbol{}
+	pkgs := map[string]bool{}
+	for _, file := range files {
+		f, err := os.Open(filepath.Join(goroot, "api", file.name))
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			pkg, name, deprecated, ok := parseAPILine(sc.Text())
+			if !ok {
+				continue
+			}
+			pkgs[pkg] = true
+			sym, ok := syms[name]
+			if !ok {
+				sym = &apiSymbol{added: file.version, deprecated: -1}
+				syms[name] = sym
+			}
+			if deprecated && sym.deprecated == -1 {
+				sym.deprecated = file.version
+			}
+		}
+		f.Close()
+		if err := sc.Err(); err != nil {
+			return nil, nil, 0, err
+		}
+	}
+
+	var paths []string
+	for pkg := range pkgs {
+		paths = append(paths, pkg)
+	}
+	sort.Strings(paths)
+	return syms, paths, files[len(files)-1].version, nil
+}
+
+// parseAPILine parses a line of an api file, such as
+//
+//	pkg archive/tar, type Header struct, Xattrs //deprecated
+//
+// and returns the package, the name of the symbol and whether the
+// line marks the symbol as deprecated.
+func parseAPILine(line string) (pkg, name string, deprecated bool, ok bool) {
+	line = apiIssueRe.ReplaceAllString(line, "")
+	if !strings.HasPrefix(line, "pkg ") {
+		return "", "", false, false
+	}
+	i := strings.Index(line, ", ")
+	if i == -1 {
+		return "", "", false, false
+	}
+	pkg, line = line[len("pkg "):i], line[i+2:]
+	if j := strings.Index(pkg, " ("); j != -1 {
+		// pkg syscall (linux-386), ...
+		pkg = pkg[:j]
+	}
+	if strings.HasSuffix(line, " //deprecated") {
+		deprecated = true
+		line = strings.TrimSuffix(line, " //deprecated")
+	}
+
+	ident := func(s string) string {
+		end := strings.IndexAny(s, " ([,")
+		if end == -1 {
+			return s
+		}
+		return s[:end]
+	}
+	switch {
+	case strings.HasPrefix(line, "func "):
+		name = pkg + "." + ident(line[len("func "):])
+	case strings.HasPrefix(line, "const "):
+		name = pkg + "." + ident(line[len("const "):])
+	case strings.HasPrefix(line, "var "):
+		name = pkg + "." + ident(line[len("var "):])
+	case strings.HasPrefix(line, "method ("):
+		// method (*Pointer[$0]) Load() *$0
+		rest := line[len("method ("):]
+		end := strings.Index(rest, ") ")
+		if end == -1 {
+			return "", "", false, false
+		}
+		recv := rest[:end]
+		if k := strings.Index(recv, "["); k != -1 {
+			recv = recv[:k]
+		}
+		star := ""
+		if strings.HasPrefix(recv, "*") {
+			star, recv = "*", recv[1:]
+		}
+		name = "(" + star + pkg + "." + recv + ")." + ident(rest[end+2:])
+	case strings.HasPrefix(line, "type "):
+		rest := line[len("type "):]
+		typ := ident(rest)
+		name = pkg + "." + typ
+		for _, kind := range []string{" struct, ", " interface, "} {
+			if strings.HasPrefix(rest, typ+kind) {
+				// a field or interface method
+				name = "(" + pkg + "." + typ + ")." + ident(rest[len(typ+kind):])
+			}
+		}
+	default:
+		return "", "", false, false
+	}
+	return pkg, name, deprecated, true
+}
+
+// Generate returns the source of stdlib.go, computed from the
+// standard library sources and api files in goroot.
+//
+// Entries in overrides are used as they are. Other symbols are
+// deprecated since the first version whose api file marks them as
+// deprecated, or, if none does, since the newest version. Without
+// better knowledge, their alternatives are assumed to be available
+// since the deprecation.
+func Generate(goroot string) ([]byte, error) {
+	syms, pkgs, version, err := readAPI(goroot)
+	if err != nil {
+		return nil, err
+	}
+
+	table := map[string]deprecated.Deprecation{}
+	for name, o := range overrides {
+		table[name] = deprecated.Deprecation{DeprecatedSince: o[0], AlternativeAvailableSince: o[1]}
+	}
+	for _, pkg := range pkgs {
+		deps, err := deprecated.Package(filepath.Join(goroot, "src", filepath.FromSlash(pkg)), pkg)
+		if err != nil {
+			return nil, err
+		}
+		for name := range deps {
+			if _, ok := overrides[name]; ok {
+				continue
+			}
+			sym, ok := syms[name]
+			if !ok {
+				// not part of the API, e.g. platform-specific code
+				// that isn't tracked
+				continue
+			}
+			d := deprecated.Deprecation{DeprecatedSince: sym.deprecated}
+			if d.DeprecatedSince == -1 {
+				d.DeprecatedSince = version
+			}
+			d.AlternativeAvailableSince = d.DeprecatedSince
+			table[name] = d
+		}
+	}
+
+	var names []string
+	for name := range table {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by gen.go from the Go 1.%d standard library. DO NOT EDIT.\n\n", version)
+	fmt.Fprintf(&buf, "package deprecated\n\n")
+	fmt.Fprintf(&buf, "// stdlibVersion is the version of Go whose standard library Stdlib\n// was generated from.\n")
+	fmt.Fprintf(&buf, "const stdlibVersion = %d\n\n", version)
+	fmt.Fprintf(&buf, "var Stdlib = map[string]Deprecation{\n")
+	for _, name := range names {
+		d := table[name]
+		fmt.Fprintf(&buf, "\t%q: {%d, %d},\n", name, d.DeprecatedSince, d.AlternativeAvailableSince)
+	}
+	fmt.Fprintf(&buf, "}\n")
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,66 @@
+package gen
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+func TestStdlibUpToDate(t *testing.T) {
+	path := filepath.Join("..", "..", "stdlib.go")
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := regexp.MustCompile(`(?m)^const stdlibVersion = (\d+)$`).FindSubmatch(got)
+	if m == nil {
+		t.Fatalf("%s doesn't declare stdlibVersion", path)
+	}
+	stdlibVersion, _ := strconv.Atoi(string(m[1]))
+
+	goroot := runtime.GOROOT()
+	_, _, version, err := readAPI(goroot)
+	if err != nil {
+		t.Skipf("can't read api files: %s", err)
+	}
+	if version != stdlibVersion {
+		// Other versions of the standard library deprecate different
+		// objects.
+		t.Skipf("Stdlib was generated from Go 1.%d, but GOROOT has Go 1.%d", stdlibVersion, version)
+	}
+	want, err := Generate(goroot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("stdlib.go is stale, run 'go generate' with Go 1.%d", version)
+	}
+}
+
+func TestParseAPILine(t *testing.T) {
+	tests := []struct {
+		line       string
+		name       string
+		deprecated bool
+	}{
+		{"pkg os, const SEEK_SET //deprecated", "os.SEEK_SET", true},
+		{"pkg archive/tar, type Header struct, Xattrs //deprecated", "(archive/tar.Header).Xattrs", true},
+		{"pkg go/doc, method (*Package) HTML(string) []uint8 #51082", "(*go/doc.Package).HTML", false},
+		{"pkg sync/atomic, method (*Pointer[$0]) Load() *$0", "(*sync/atomic.Pointer).Load", false},
+		{"pkg slices, func Clip[$0 interface{ ~[]$1 }, $1 interface{}]($0) $0", "slices.Clip", false},
+		{"pkg syscall (linux-386), func Setuid(int) error", "syscall.Setuid", false},
+		{"pkg io, type Reader interface { Read }", "io.Reader", false},
+		{"pkg image/jpeg, type Reader interface { Read, ReadByte }", "image/jpeg.Reader", false},
+		{"pkg image/jpeg, type Reader interface, ReadByte() (uint8, error)", "(image/jpeg.Reader).ReadByte", false},
+	}
+	for _, tt := range tests {
+		_, name, deprecated, ok := parseAPILine(tt.line)
+		if !ok || name != tt.name || deprecated != tt.deprecated {
+			t.Errorf("parseAPILine(%q) = %q, %t, %t, want %q, %t", tt.line, name, deprecated, ok, tt.name, tt.deprecated)
+		}
+	}
+}
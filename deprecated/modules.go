@@ -0,0 +1,196 @@
+package deprecated
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// escapePath escapes a module path the way the module cache does,
+// replacing upper-case letters with an exclamation mark followed by
+// the lower-case letter.
+func escapePath(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		if unicode.IsUpper(r) {
+			b.WriteByte('!')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// ModuleCache returns the root of the module cache, given the
+// directory dir in which version of module modPath was extracted. It
+// returns false if dir isn't in a module cache, for example because
+// the module has been replaced by a local directory.
+func ModuleCache(dir, modPath, version string) (string, bool) {
+	suffix := string(filepath.Separator) + filepath.FromSlash(escapePath(modPath)) + "@" + version
+	if !strings.HasSuffix(dir, suffix) {
+		return "", false
+	}
+	return strings.TrimSuffix(dir, suffix), true
+}
+
+// ModuleDir returns the directory of version of module modPath in the
+// module cache rooted at cache.
+func ModuleDir(cache, modPath, version string) string {
+	return filepath.Join(cache, filepath.FromSlash(escapePath(modPath))+"@"+version)
+}
+
+// ModuleVersions returns the released versions of module modPath that
+// have been extracted into the module cache rooted at cache, from
+// oldest to newest. Pre-release and pseudo-versions are omitted.
+func ModuleVersions(cache, modPath string) ([]string, error) {
+	escaped := filepath.FromSlash(escapePath(modPath))
+	fis, err := ioutil.ReadDir(filepath.Join(cache, filepath.Dir(escaped)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	prefix := filepath.Base(escaped) + "@"
+	var versions []string
+	for _, fi := range fis {
+		if !fi.IsDir() || !strings.HasPrefix(fi.Name(), prefix) {
+			continue
+		}
+		v := fi.Name()[len(prefix):]
+		if _, ok := parseVersion(v); !ok {
+			continue
+		}
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return CompareVersions(versions[i], versions[j]) < 0
+	})
+	return versions, nil
+}
+
+// parseVersion parses released semantic versions such as v1.2.3 and
+// v2.0.0+incompatible.
+func parseVersion(v string) ([3]int, bool) {
+	var out [3]int
+	if !strings.HasPrefix(v, "v") {
+		return out, false
+	}
+	v = strings.TrimSuffix(v[1:], "+incompatible")
+	parts := strings.Split(v, ".")
+	if len(parts) != 3 {
+		return out, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return out, false
+		}
+		out[i] = n
+	}
+	return out, true
+}
+
+// CompareVersions compares two released semantic versions and returns
+// -1, 0 or 1. Versions that can't be parsed sort before all others.
+func CompareVersions(v, w string) int {
+	pv, okv := parseVersion(v)
+	pw, okw := parseVersion(w)
+	switch {
+	case !okv && !okw:
+		return strings.Compare(v, w)
+	case !okv:
+		return -1
+	case !okw:
+		return 1
+	}
+	for i := range pv {
+		if pv[i] != pw[i] {
+			if pv[i] < pw[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// A ModuleDeprecation describes in which versions of a module an
+// object is deprecated.
+type ModuleDeprecation struct {
+	// Since is the version from which on the object has been
+	// deprecated, up to the version in use. It is empty if the
+	// version in use doesn't deprecate the object, or if the module
+	// cache doesn't contain an older version that doesn't.
+	Since string
+	// Upstream is the oldest version newer than the one in use that
+	// deprecates the object, if the version in use doesn't.
+	Upstream string
+	// Alternative is the deprecation message of the newest version
+	// that deprecates the object.
+	Alternative string
+}
+
+// ModuleHistory compares the package pkgPath of version of module
+// modPath with the versions of the package found in the module cache
+// rooted at cache, and returns the objects that are deprecated in any
+// of them. Objects are keyed by names in the format used by Stdlib.
+func ModuleHistory(cache, modPath, version, pkgPath string) (map[string]ModuleDeprecation, error) {
+	if _, ok := parseVersion(version); !ok {
+		// We can't tell which versions are older or newer than a
+		// pseudo-version.
+		return nil, nil
+	}
+	versions, err := ModuleVersions(cache, modPath)
+	if err != nil {
+		return nil, err
+	}
+	rel := filepath.FromSlash(strings.TrimPrefix(pkgPath, modPath))
+
+	out := map[string]ModuleDeprecation{}
+	// prev records the objects that were deprecated in the previous
+	// version, excluding the oldest one, for which we can't know
+	// whether the deprecation is new.
+	var prev map[string]bool
+	for i, v := range versions {
+		deps, err := Package(ModuleDir(cache, modPath, v)+rel, pkgPath)
+		if err != nil {
+			// The package doesn't exist in this version.
+			deps = nil
+		}
+		cur := map[string]bool{}
+		cmp := CompareVersions(v, version)
+		for name, alt := range deps {
+			cur[name] = true
+			d := out[name]
+			d.Alternative = alt
+			switch {
+			case cmp <= 0:
+				if i > 0 && !prev[name] {
+					d.Since = v
+				}
+			case cmp > 0:
+				if d.Upstream == "" && d.Since == "" && !prev[name] {
+					d.Upstream = v
+				}
+			}
+			out[name] = d
+		}
+		if cmp <= 0 {
+			for name, d := range out {
+				if !cur[name] {
+					// No longer deprecated, or not in the version in
+					// use.
+					d.Since = ""
+					out[name] = d
+				}
+			}
+		}
+		prev = cur
+	}
+	return out, nil
+}
@@ -0,0 +1,59 @@
+package deprecated
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestModuleHistory(t *testing.T) {
+	cache, err := ioutil.TempDir("", "modcache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(cache)
+
+	versions := map[string]string{
+		"v1.0.0":  "func A() {}\nfunc B() {}\nfunc C() {}\n",
+		"v1.1.0":  "// Deprecated: Use A2.\nfunc A() {}\nfunc B() {}\nfunc C() {}\n",
+		"v1.2.0":  "// Deprecated: Use A2.\nfunc A() {}\nfunc B() {}\n// Deprecated: Use C2.\nfunc C() {}\n",
+		"v1.10.0": "// Deprecated: Use A2.\nfunc A() {}\n// Deprecated: Use B2.\nfunc B() {}\n// Deprecated: Use C2.\nfunc C() {}\n",
+	}
+	for v, src := range versions {
+		dir := filepath.Join(cache, "example.com", "!my!lib@"+v, "sub")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, "sub.go"), []byte("package sub\n\n"+src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	vs, err := ModuleVersions(cache, "example.com/MyLib")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"v1.0.0", "v1.1.0", "v1.2.0", "v1.10.0"}; !reflect.DeepEqual(vs, want) {
+		t.Errorf("got versions %q, want %q", vs, want)
+	}
+
+	got, err := ModuleHistory(cache, "example.com/MyLib", "v1.1.0", "example.com/MyLib/sub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]ModuleDeprecation{
+		"example.com/MyLib/sub.A": {Since: "v1.1.0", Alternative: "Use A2."},
+		"example.com/MyLib/sub.B": {Upstream: "v1.10.0", Alternative: "Use B2."},
+		"example.com/MyLib/sub.C": {Upstream: "v1.2.0", Alternative: "Use C2."},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	dir := filepath.Join(cache, "example.com", "!my!lib@v1.1.0")
+	if root, ok := ModuleCache(dir, "example.com/MyLib", "v1.1.0"); !ok || root != cache {
+		t.Errorf("ModuleCache(%q) = %q, %t, want %q, true", dir, root, ok, cache)
+	}
+}
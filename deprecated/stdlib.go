@@ -1,112 +1,202 @@
+// Code generated by gen.go from the Go 1.27 standard library. DO NOT EDIT.
+
 package deprecated
 
-type Deprecation struct {
-	DeprecatedSince           int
-	AlternativeAvailableSince int
-}
+// stdlibVersion is the version of Go whose standard library Stdlib
+// was generated from.
+const stdlibVersion = 27
 
 var Stdlib = map[string]Deprecation{
-	"image/jpeg.Reader": {4, 0},
-	// FIXME(dh): AllowBinary isn't being detected as deprecated
-	// because the comment has a newline right after "Deprecated:"
-	"go/build.AllowBinary":                        {7, 7},
-	"(archive/zip.FileHeader).CompressedSize":     {1, 1},
-	"(archive/zip.FileHeader).UncompressedSize":   {1, 1},
-	"(archive/zip.FileHeader).ModifiedTime":       {10, 10},
-	"(archive/zip.FileHeader).ModifiedDate":       {10, 10},
-	"(*archive/zip.FileHeader).ModTime":           {10, 10},
-	"(*archive/zip.FileHeader).SetModTime":        {10, 10},
-	"(go/doc.Package).Bugs":                       {1, 1},
-	"os.SEEK_SET":                                 {7, 7},
-	"os.SEEK_CUR":                                 {7, 7},
-	"os.SEEK_END":                                 {7, 7},
-	"(net.Dialer).Cancel":                         {7, 7},
-	"runtime.CPUProfile":                          {9, 0},
-	"compress/flate.ReadError":                    {6, 6},
-	"compress/flate.WriteError":                   {6, 6},
-	"path/filepath.HasPrefix":                     {0, 0},
-	"(net/http.Transport).Dial":                   {7, 7},
-	"(*net/http.Transport).CancelRequest":         {6, 5},
-	"net/http.ErrWriteAfterFlush":                 {7, 0},
-	"net/http.ErrHeaderTooLong":                   {8, 0},
-	"net/http.ErrShortBody":                       {8, 0},
-	"net/http.ErrMissingContentLength":            {8, 0},
-	"net/http/httputil.ErrPersistEOF":             {0, 0},
-	"net/http/httputil.ErrClosed":                 {0, 0},
-	"net/http/httputil.ErrPipeline":               {0, 0},
-	"net/http/httputil.ServerConn":                {0, 0},
-	"net/http/httputil.NewServerConn":             {0, 0},
-	"net/http/httputil.ClientConn":                {0, 0},
-	"net/http/httputil.NewClientConn":             {0, 0},
-	"net/http/httputil.NewProxyClientConn":        {0, 0},
-	"(net/http.Request).Cancel":                   {7, 7},
-	"(text/template/parse.PipeNode).Line":         {1, 1},
-	"(text/template/parse.ActionNode).Line":       {1, 1},
-	"(text/template/parse.BranchNode).Line":       {1, 1},
-	"(text/template/parse.TemplateNode).Line":     {1, 1},
-	"database/sql/driver.ColumnConverter":         {9, 9},
-	"database/sql/driver.Execer":                  {8, 8},
-	"database/sql/driver.Queryer":                 {8, 8},
-	"(database/sql/driver.Conn).Begin":            {8, 8},
-	"(database/sql/driver.Stmt).Exec":             {8, 8},
-	"(database/sql/driver.Stmt).Query":            {8, 8},
-	"syscall.StringByteSlice":                     {1, 1},
-	"syscall.StringBytePtr":                       {1, 1},
-	"syscall.StringSlicePtr":                      {1, 1},
-	"syscall.StringToUTF16":                       {1, 1},
-	"syscall.StringToUTF16Ptr":                    {1, 1},
-	"(*regexp.Regexp).Copy":                       {12, 12},
-	"(archive/tar.Header).Xattrs":                 {10, 10},
-	"archive/tar.TypeRegA":                        {11, 1},
-	"go/types.NewInterface":                       {11, 11},
-	"(*go/types.Interface).Embedded":              {11, 11},
-	"go/importer.For":                             {12, 12},
-	"encoding/json.InvalidUTF8Error":              {2, 2},
-	"encoding/json.UnmarshalFieldError":           {2, 2},
-	"encoding/csv.ErrTrailingComma":               {2, 2},
-	"(encoding/csv.Reader).TrailingComma":         {2, 2},
-	"(net.Dialer).DualStack":                      {12, 12},
-	"net/http.ErrUnexpectedTrailer":               {12, 12},
-	"net/http.CloseNotifier":                      {11, 7},
-	"net/http.ProtocolError":                      {8, 8},
-	"(crypto/x509.CertificateRequest).Attributes": {5, 3},
-	// This function has no alternative, but also no purpose.
-	"(*crypto/rc4.Cipher).Reset":                     {12, 0},
-	"(net/http/httptest.ResponseRecorder).HeaderMap": {11, 7},
-
-	// All of these have been deprecated in favour of external libraries
-	"syscall.AttachLsf":             {7, 0},
-	"syscall.DetachLsf":             {7, 0},
-	"syscall.LsfSocket":             {7, 0},
-	"syscall.SetLsfPromisc":         {7, 0},
-	"syscall.LsfJump":               {7, 0},
-	"syscall.LsfStmt":               {7, 0},
-	"syscall.BpfStmt":               {7, 0},
-	"syscall.BpfJump":               {7, 0},
-	"syscall.BpfBuflen":             {7, 0},
-	"syscall.SetBpfBuflen":          {7, 0},
-	"syscall.BpfDatalink":           {7, 0},
-	"syscall.SetBpfDatalink":        {7, 0},
-	"syscall.SetBpfPromisc":         {7, 0},
-	"syscall.FlushBpf":              {7, 0},
-	"syscall.BpfInterface":          {7, 0},
-	"syscall.SetBpfInterface":       {7, 0},
-	"syscall.BpfTimeout":            {7, 0},
-	"syscall.SetBpfTimeout":         {7, 0},
-	"syscall.BpfStats":              {7, 0},
-	"syscall.SetBpfImmediate":       {7, 0},
-	"syscall.SetBpf":                {7, 0},
-	"syscall.CheckBpfVersion":       {7, 0},
-	"syscall.BpfHeadercmpl":         {7, 0},
-	"syscall.SetBpfHeadercmpl":      {7, 0},
-	"syscall.RouteRIB":              {8, 0},
-	"syscall.RoutingMessage":        {8, 0},
-	"syscall.RouteMessage":          {8, 0},
-	"syscall.InterfaceMessage":      {8, 0},
-	"syscall.InterfaceAddrMessage":  {8, 0},
-	"syscall.ParseRoutingMessage":   {8, 0},
-	"syscall.ParseRoutingSockaddr":  {8, 0},
-	"InterfaceAnnounceMessage":      {7, 0},
-	"InterfaceMulticastAddrMessage": {7, 0},
-	"syscall.FormatMessage":         {5, 0},
+	"(*archive/zip.FileHeader).ModTime":                       {10, 10},
+	"(*archive/zip.FileHeader).SetModTime":                    {10, 10},
+	"(*crypto/elliptic.CurveParams).Add":                      {21, 21},
+	"(*crypto/elliptic.CurveParams).Double":                   {21, 21},
+	"(*crypto/elliptic.CurveParams).IsOnCurve":                {21, 21},
+	"(*crypto/elliptic.CurveParams).ScalarBaseMult":           {21, 21},
+	"(*crypto/elliptic.CurveParams).ScalarMult":               {21, 21},
+	"(*crypto/rc4.Cipher).Reset":                              {12, 0},
+	"(*crypto/tls.Config).BuildNameToCertificate":             {16, 16},
+	"(*crypto/x509.CertPool).Subjects":                        {18, 18},
+	"(*crypto/x509.Certificate).CheckCRLSignature":            {19, 19},
+	"(*crypto/x509.Certificate).CreateCRL":                    {19, 19},
+	"(*debug/gosym.LineTable).LineToPC":                       {16, 16},
+	"(*debug/gosym.LineTable).PCToLine":                       {16, 16},
+	"(*go/types.Interface).Embedded":                          {11, 11},
+	"(*net/http.Transport).CancelRequest":                     {6, 5},
+	"(*regexp.Regexp).Copy":                                   {12, 12},
+	"(archive/tar.Header).Xattrs":                             {10, 10},
+	"(archive/zip.FileHeader).CompressedSize":                 {1, 1},
+	"(archive/zip.FileHeader).ModifiedDate":                   {10, 10},
+	"(archive/zip.FileHeader).ModifiedTime":                   {10, 10},
+	"(archive/zip.FileHeader).UncompressedSize":               {1, 1},
+	"(crypto/ecdsa.PrivateKey).D":                             {26, 26},
+	"(crypto/ecdsa.PublicKey).X":                              {26, 26},
+	"(crypto/ecdsa.PublicKey).Y":                              {26, 26},
+	"(crypto/elliptic.Curve).Add":                             {21, 21},
+	"(crypto/elliptic.Curve).Double":                          {21, 21},
+	"(crypto/elliptic.Curve).IsOnCurve":                       {21, 21},
+	"(crypto/elliptic.Curve).ScalarBaseMult":                  {21, 21},
+	"(crypto/elliptic.Curve).ScalarMult":                      {21, 21},
+	"(crypto/rsa.PrecomputedValues).CRTValues":                {21, 21},
+	"(crypto/tls.Config).NameToCertificate":                   {16, 16},
+	"(crypto/tls.Config).PreferServerCipherSuites":            {18, 18},
+	"(crypto/tls.Config).Rand":                                {27, 27},
+	"(crypto/tls.Config).SessionTicketKey":                    {16, 16},
+	"(crypto/tls.ConnectionState).NegotiatedProtocolIsMutual": {16, 16},
+	"(crypto/x509.CertificateRequest).Attributes":             {5, 3},
+	"(crypto/x509.RevocationList).RevokedCertificates":        {21, 21},
+	"(database/sql/driver.Conn).Begin":                        {8, 8},
+	"(database/sql/driver.Stmt).Exec":                         {8, 8},
+	"(database/sql/driver.Stmt).Query":                        {8, 8},
+	"(encoding/csv.Reader).TrailingComma":                     {2, 2},
+	"(go/doc.Package).Bugs":                                   {1, 1},
+	"(net.Dialer).Cancel":                                     {7, 7},
+	"(net.Dialer).DualStack":                                  {12, 12},
+	"(net.Error).Temporary":                                   {18, 18},
+	"(net/http.Request).Cancel":                               {7, 7},
+	"(net/http.Transport).Dial":                               {7, 7},
+	"(net/http.Transport).DialTLS":                            {16, 16},
+	"(net/http/httptest.ResponseRecorder).HeaderMap":          {11, 7},
+	"(net/http/httputil.ReverseProxy).Director":               {26, 26},
+	"(reflect.Value).InterfaceData":                           {17, 17},
+	"(text/template/parse.ActionNode).Line":                   {1, 1},
+	"(text/template/parse.BranchNode).Line":                   {1, 1},
+	"(text/template/parse.PipeNode).Line":                     {1, 1},
+	"(text/template/parse.TemplateNode).Line":                 {1, 1},
+	"archive/tar.TypeRegA":                                    {11, 1},
+	"bytes.Title":                                             {18, 18},
+	"compress/flate.ReadError":                                {6, 6},
+	"compress/flate.WriteError":                               {6, 6},
+	"crypto/cipher.NewCFBDecrypter":                           {24, 24},
+	"crypto/cipher.NewCFBEncrypter":                           {24, 24},
+	"crypto/cipher.NewOFB":                                    {24, 24},
+	"crypto/elliptic.GenerateKey":                             {21, 21},
+	"crypto/elliptic.Marshal":                                 {21, 21},
+	"crypto/elliptic.Unmarshal":                               {21, 21},
+	"crypto/rsa.DecryptPKCS1v15":                              {26, 26},
+	"crypto/rsa.DecryptPKCS1v15SessionKey":                    {26, 26},
+	"crypto/rsa.EncryptPKCS1v15":                              {26, 26},
+	"crypto/rsa.GenerateMultiPrimeKey":                        {21, 21},
+	"crypto/rsa.PKCS1v15DecryptOptions":                       {26, 26},
+	"crypto/tls.VersionSSL30":                                 {16, 16},
+	"crypto/x509.DecryptPEMBlock":                             {16, 16},
+	"crypto/x509.EncryptPEMBlock":                             {16, 16},
+	"crypto/x509.IsEncryptedPEMBlock":                         {16, 16},
+	"crypto/x509.ParseCRL":                                    {19, 19},
+	"crypto/x509.ParseDERCRL":                                 {19, 19},
+	"crypto/x509/pkix.CertificateList":                        {19, 19},
+	"crypto/x509/pkix.TBSCertificateList":                     {19, 19},
+	"database/sql/driver.ColumnConverter":                     {9, 9},
+	"database/sql/driver.Execer":                              {8, 8},
+	"database/sql/driver.Queryer":                             {8, 8},
+	"encoding/csv.ErrTrailingComma":                           {2, 2},
+	"encoding/json.InvalidUTF8Error":                          {2, 2},
+	"encoding/json.UnmarshalFieldError":                       {2, 2},
+	"go/ast.FilterFuncDuplicates":                             {25, 25},
+	"go/ast.FilterImportDuplicates":                           {25, 25},
+	"go/ast.FilterPackage":                                    {25, 25},
+	"go/ast.FilterUnassociatedComments":                       {25, 25},
+	"go/ast.Importer":                                         {21, 21},
+	"go/ast.MergeMode":                                        {25, 25},
+	"go/ast.MergePackageFiles":                                {25, 25},
+	"go/ast.NewPackage":                                       {21, 21},
+	"go/ast.Object":                                           {21, 21},
+	"go/ast.Package":                                          {21, 21},
+	"go/ast.PackageExports":                                   {25, 25},
+	"go/ast.Scope":                                            {21, 21},
+	"go/build.AllowBinary":                                    {7, 7},
+	"go/doc.Synopsis":                                         {19, 19},
+	"go/doc.ToHTML":                                           {19, 19},
+	"go/doc.ToText":                                           {19, 19},
+	"go/importer.For":                                         {12, 12},
+	"go/parser.ParseDir":                                      {25, 25},
+	"go/types.NewInterface":                                   {11, 11},
+	"go/types.NewSignature":                                   {18, 18},
+	"html/template.ErrJSTemplate":                             {22, 22},
+	"image.ZP":                                                {16, 16},
+	"image.ZR":                                                {16, 16},
+	"image/jpeg.Reader":                                       {4, 0},
+	"io/ioutil.Discard":                                       {19, 19},
+	"io/ioutil.NopCloser":                                     {19, 19},
+	"io/ioutil.ReadAll":                                       {19, 19},
+	"io/ioutil.ReadDir":                                       {19, 19},
+	"io/ioutil.ReadFile":                                      {19, 19},
+	"io/ioutil.TempDir":                                       {19, 19},
+	"io/ioutil.TempFile":                                      {19, 19},
+	"io/ioutil.WriteFile":                                     {19, 19},
+	"math/rand.Read":                                          {20, 20},
+	"math/rand.Seed":                                          {20, 20},
+	"net/http.CloseNotifier":                                  {11, 7},
+	"net/http.ErrHeaderTooLong":                               {8, 0},
+	"net/http.ErrMissingContentLength":                        {8, 0},
+	"net/http.ErrShortBody":                                   {8, 0},
+	"net/http.ErrUnexpectedTrailer":                           {12, 12},
+	"net/http.ErrWriteAfterFlush":                             {7, 0},
+	"net/http.ProtocolError":                                  {8, 8},
+	"net/http/httputil.ClientConn":                            {0, 0},
+	"net/http/httputil.ErrClosed":                             {0, 0},
+	"net/http/httputil.ErrPersistEOF":                         {0, 0},
+	"net/http/httputil.ErrPipeline":                           {0, 0},
+	"net/http/httputil.NewClientConn":                         {0, 0},
+	"net/http/httputil.NewProxyClientConn":                    {0, 0},
+	"net/http/httputil.NewServerConn":                         {0, 0},
+	"net/http/httputil.ServerConn":                            {0, 0},
+	"os.SEEK_CUR":                                             {7, 7},
+	"os.SEEK_END":                                             {7, 7},
+	"os.SEEK_SET":                                             {7, 7},
+	"path/filepath.HasPrefix":                                 {0, 0},
+	"reflect.PtrTo":                                           {22, 22},
+	"reflect.SliceHeader":                                     {21, 21},
+	"reflect.StringHeader":                                    {21, 21},
+	"runtime.CPUProfile":                                      {9, 0},
+	"runtime.GOROOT":                                          {24, 24},
+	"strings.Title":                                           {18, 18},
+	"syscall.AttachLsf":                                       {7, 0},
+	"syscall.BpfBuflen":                                       {7, 0},
+	"syscall.BpfDatalink":                                     {7, 0},
+	"syscall.BpfHeadercmpl":                                   {7, 0},
+	"syscall.BpfInterface":                                    {7, 0},
+	"syscall.BpfJump":                                         {7, 0},
+	"syscall.BpfStats":                                        {7, 0},
+	"syscall.BpfStmt":                                         {7, 0},
+	"syscall.BpfTimeout":                                      {7, 0},
+	"syscall.CheckBpfVersion":                                 {7, 0},
+	"syscall.CreateIoCompletionPort":                          {17, 17},
+	"syscall.DetachLsf":                                       {7, 0},
+	"syscall.FlushBpf":                                        {7, 0},
+	"syscall.FormatMessage":                                   {5, 0},
+	"syscall.GetQueuedCompletionStatus":                       {17, 17},
+	"syscall.InterfaceAddrMessage":                            {8, 0},
+	"syscall.InterfaceAnnounceMessage":                        {7, 0},
+	"syscall.InterfaceMessage":                                {8, 0},
+	"syscall.InterfaceMulticastAddrMessage":                   {7, 0},
+	"syscall.LsfJump":                                         {7, 0},
+	"syscall.LsfSocket":                                       {7, 0},
+	"syscall.LsfStmt":                                         {7, 0},
+	"syscall.ParseRoutingMessage":                             {8, 0},
+	"syscall.ParseRoutingSockaddr":                            {8, 0},
+	"syscall.PostQueuedCompletionStatus":                      {17, 17},
+	"syscall.RouteMessage":                                    {8, 0},
+	"syscall.RouteRIB":                                        {8, 0},
+	"syscall.RoutingMessage":                                  {8, 0},
+	"syscall.SetBpf":                                          {7, 0},
+	"syscall.SetBpfBuflen":                                    {7, 0},
+	"syscall.SetBpfDatalink":                                  {7, 0},
+	"syscall.SetBpfHeadercmpl":                                {7, 0},
+	"syscall.SetBpfImmediate":                                 {7, 0},
+	"syscall.SetBpfInterface":                                 {7, 0},
+	"syscall.SetBpfPromisc":                                   {7, 0},
+	"syscall.SetBpfTimeout":                                   {7, 0},
+	"syscall.SetLsfPromisc":                                   {7, 0},
+	"syscall.StringBytePtr":                                   {1, 1},
+	"syscall.StringByteSlice":                                 {1, 1},
+	"syscall.StringSlicePtr":                                  {1, 1},
+	"syscall.StringToUTF16":                                   {1, 1},
+	"syscall.StringToUTF16Ptr":                                {1, 1},
+	"syscall.Syscall":                                         {18, 18},
+	"syscall.Syscall12":                                       {18, 18},
+	"syscall.Syscall15":                                       {18, 18},
+	"syscall.Syscall18":                                       {18, 18},
+	"syscall.Syscall6":                                        {18, 18},
+	"syscall.Syscall9":                                        {18, 18},
 }
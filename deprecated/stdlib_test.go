@@ -0,0 +1,22 @@
+package deprecated
+
+import "testing"
+
+func TestParagraph(t *testing.T) {
+	tests := []struct {
+		doc  string
+		alt  string
+		want bool
+	}{
+		{"Foo does things.\n\nDeprecated: Use Bar instead.\n", "Use Bar instead.", true},
+		{"Foo does things.\n\nDeprecated:\nUse Bar\ninstead.\n", "Use Bar instead.", true},
+		{"Deprecated: Use Bar.\n\nFoo does things.\n", "Use Bar.", true},
+		{"Foo is not Deprecated: at all.\n", "", false},
+	}
+	for _, tt := range tests {
+		alt, ok := Paragraph(tt.doc)
+		if alt != tt.alt || ok != tt.want {
+			t.Errorf("Paragraph(%q) = %q, %t, want %q, %t", tt.doc, alt, ok, tt.alt, tt.want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package functions
 
 import (
+	"context"
 	"go/types"
 	"sync"
 
@@ -45,6 +46,12 @@ var stdlibDescs = map[string]Description{
 	"(*math/rand.Rand).Read": {NilError: true},
 }
 
+// Known returns what is known about fn without analysing it, which is
+// only the case for some functions of the standard library.
+func Known(fn *ssa.Function) Description {
+	return stdlibDescs[fn.RelString(nil)]
+}
+
 type Description struct {
 	// The function is known to be pure
 	Pure bool
@@ -64,6 +71,7 @@ type Description struct {
 type descriptionEntry struct {
 	ready  chan struct{}
 	result Description
+	err    error
 }
 
 type Descriptions struct {
@@ -80,32 +88,57 @@ func NewDescriptions(prog *ssa.Program) *Descriptions {
 }
 
 func (d *Descriptions) Get(fn *ssa.Function) Description {
-	d.mu.Lock()
-	fd := d.cache[fn]
-	if fd == nil {
-		fd = &descriptionEntry{
-			ready: make(chan struct{}),
+	desc, _ := d.GetContext(context.Background(), fn)
+	return desc
+}
+
+// GetContext is like Get, but gives up once ctx is done. Descriptions
+// that couldn't be computed in time aren't cached, so that other
+// callers can try again.
+func (d *Descriptions) GetContext(ctx context.Context, fn *ssa.Function) (Description, error) {
+	for {
+		d.mu.Lock()
+		fd := d.cache[fn]
+		if fd == nil {
+			fd = &descriptionEntry{
+				ready: make(chan struct{}),
+			}
+			d.cache[fn] = fd
+			d.mu.Unlock()
+
+			{
+				fd.result = stdlibDescs[fn.RelString(nil)]
+				fd.result.Pure = fd.result.Pure || d.IsPure(fn)
+				fd.result.Stub = fd.result.Stub || d.IsStub(fn)
+				fd.result.Infinite = fd.result.Infinite || !terminates(fn)
+				fd.result.Ranges, fd.err = vrp.BuildGraph(fn).SolveContext(ctx)
+				fd.result.Loops = findLoops(fn)
+				fd.result.NilError = fd.result.NilError || IsNilError(fn)
+				fd.result.ConcreteReturnTypes = concreteReturnTypes(fn)
+			}
+
+			if fd.err != nil {
+				d.mu.Lock()
+				delete(d.cache, fn)
+				d.mu.Unlock()
+			}
+			close(fd.ready)
+			return fd.result, fd.err
 		}
-		d.cache[fn] = fd
 		d.mu.Unlock()
 
-		{
-			fd.result = stdlibDescs[fn.RelString(nil)]
-			fd.result.Pure = fd.result.Pure || d.IsPure(fn)
-			fd.result.Stub = fd.result.Stub || d.IsStub(fn)
-			fd.result.Infinite = fd.result.Infinite || !terminates(fn)
-			fd.result.Ranges = vrp.BuildGraph(fn).Solve()
-			fd.result.Loops = findLoops(fn)
-			fd.result.NilError = fd.result.NilError || IsNilError(fn)
-			fd.result.ConcreteReturnTypes = concreteReturnTypes(fn)
+		select {
+		case <-fd.ready:
+			if fd.err != nil {
+				// The caller computing the description gave up;
+				// try again with our own context.
+				continue
+			}
+			return fd.result, nil
+		case <-ctx.Done():
+			return Description{}, ctx.Err()
 		}
-
-		close(fd.ready)
-	} else {
-		d.mu.Unlock()
-		<-fd.ready
 	}
-	return fd.result
 }
 
 func IsNilError(fn *ssa.Function) bool {
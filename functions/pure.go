@@ -14,6 +14,12 @@ import (
 // instruction, which must be either returning only constant values or
 // a panic.
 func (d *Descriptions) IsStub(fn *ssa.Function) bool {
+	return IsStub(fn)
+}
+
+// IsStub is like Descriptions.IsStub, for callers without
+// descriptions.
+func IsStub(fn *ssa.Function) bool {
 	if len(fn.Blocks) == 0 {
 		return true
 	}
@@ -41,6 +47,26 @@ func (d *Descriptions) IsStub(fn *ssa.Function) bool {
 }
 
 func (d *Descriptions) IsPure(fn *ssa.Function) bool {
+	return IsPureWith(fn, func(callee *ssa.Function) bool {
+		// TODO(dh): ideally, IsPure wouldn't be responsible for
+		// avoiding infinite recursion, but FunctionDescriptions
+		// would be.
+		node := d.CallGraph.CreateNode(callee)
+		if callgraph.PathSearch(node, func(other *callgraph.Node) bool {
+			return other.Func == fn
+		}) != nil {
+			return false
+		}
+		return d.Get(callee).Pure
+	})
+}
+
+// IsPureWith reports whether fn is pure, using calleePure to decide
+// whether the functions it calls, other than itself, are pure. This
+// lets callers without a call graph of the whole program compute
+// purity, for example from facts about dependencies. calleePure is
+// responsible for avoiding infinite recursion.
+func IsPureWith(fn *ssa.Function, calleePure func(callee *ssa.Function) bool) bool {
 	if fn.Signature.Results().Len() == 0 {
 		// A function with no return values is empty or is doing some
 		// work we cannot see (for example because of build tags);
@@ -67,16 +93,7 @@ func (d *Descriptions) IsPure(fn *ssa.Function) bool {
 				if common.StaticCallee() == nil {
 					return false
 				}
-				// TODO(dh): ideally, IsPure wouldn't be responsible
-				// for avoiding infinite recursion, but
-				// FunctionDescriptions would be.
-				node := d.CallGraph.CreateNode(common.StaticCallee())
-				if callgraph.PathSearch(node, func(other *callgraph.Node) bool {
-					return other.Func == fn
-				}) != nil {
-					return false
-				}
-				if !d.Get(common.StaticCallee()).Pure {
+				if !calleePure(common.StaticCallee()) {
 					return false
 				}
 			}
@@ -0,0 +1,261 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"reflect"
+	"sort"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
+	"honnef.co/go/tools/ssa"
+)
+
+// A FactChecker is a Checker that computes facts about packages and
+// their objects, which its checks use when checking the packages that
+// import them. Because the facts of a package only depend on the
+// package and the facts of its dependencies, checks don't have to
+// look at the whole program, and drivers that check one package at a
+// time, such as go vet, can pass facts between packages and cache
+// them.
+type FactChecker interface {
+	Checker
+	// ComputeFacts exports the facts of pkg, whose functions are fns,
+	// to facts. It is called once for each package loaded from
+	// source, after its functions have been prepared and the facts of
+	// its dependencies have been computed, and before checks that
+	// need its SSA form start. It is called concurrently for
+	// different packages.
+	ComputeFacts(pkg *packages.Package, fns []*ssa.Function, facts *Facts)
+}
+
+// Facts stores the facts that FactCheckers compute about packages
+// and objects. A fact is a pointer to a value that can be encoded as
+// JSON, and the type of the value identifies the kind of fact. Only
+// package-level objects, the methods of package-level types and the
+// fields of struct types reachable from package-level declarations
+// can have facts. It is safe to use Facts concurrently.
+type Facts struct {
+	mu    sync.Mutex
+	facts map[factKey]interface{}
+	// paths caches the object paths of packages' objects.
+	paths map[*types.Package]map[types.Object]string
+}
+
+type factKey struct {
+	pkg  string
+	obj  string // empty for package facts
+	kind string
+}
+
+// encodedFact is the encoding of a single fact.
+type encodedFact struct {
+	Package string          `json:"package"`
+	Object  string          `json:"object,omitempty"`
+	Kind    string          `json:"kind"`
+	Fact    json.RawMessage `json:"fact"`
+}
+
+func NewFacts() *Facts {
+	return &Facts{
+		facts: map[factKey]interface{}{},
+		paths: map[*types.Package]map[types.Object]string{},
+	}
+}
+
+func factKind(fact interface{}) string {
+	T := reflect.TypeOf(fact)
+	if T.Kind() != reflect.Ptr {
+		panic(fmt.Sprintf("fact of type %s is not a pointer", T))
+	}
+	return T.String()
+}
+
+// objectPath returns the path of obj within its package, such as
+// "Reader" or "Reader.Read", and false if obj can't have facts. f.mu
+// must be held.
+func (f *Facts) objectPath(obj types.Object) (string, bool) {
+	if obj == nil || obj.Pkg() == nil {
+		return "", false
+	}
+	paths, ok := f.paths[obj.Pkg()]
+	if !ok {
+		paths = map[types.Object]string{}
+		packageObjects(obj.Pkg(), func(path string, obj types.Object) {
+			paths[obj] = path
+		})
+		f.paths[obj.Pkg()] = paths
+	}
+	path, ok := paths[obj]
+	return path, ok
+}
+
+// get copies the fact stored under key into fact. f.mu must be held.
+func (f *Facts) get(key factKey, fact interface{}) bool {
+	v, ok := f.facts[key]
+	if !ok {
+		return false
+	}
+	if raw, ok := v.(json.RawMessage); ok {
+		// The fact was decoded, and this is the first time we know
+		// its type.
+		v = reflect.New(reflect.TypeOf(fact).Elem()).Interface()
+		if err := json.Unmarshal(raw, v); err != nil {
+			return false
+		}
+		f.facts[key] = v
+	}
+	reflect.ValueOf(fact).Elem().Set(reflect.ValueOf(v).Elem())
+	return true
+}
+
+// ExportObjectFact records fact about obj, replacing any earlier fact
+// of the same kind. Facts about objects that can't have facts are
+// dropped.
+func (f *Facts) ExportObjectFact(obj types.Object, fact interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	path, ok := f.objectPath(obj)
+	if !ok {
+		return
+	}
+	f.facts[factKey{obj.Pkg().Path(), path, factKind(fact)}] = fact
+}
+
+// ImportObjectFact copies the fact about obj of fact's kind into fact
+// and reports whether there was such a fact.
+func (f *Facts) ImportObjectFact(obj types.Object, fact interface{}) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	path, ok := f.objectPath(obj)
+	if !ok {
+		return false
+	}
+	return f.get(factKey{obj.Pkg().Path(), path, factKind(fact)}, fact)
+}
+
+// ExportPackageFact records fact about pkg, replacing any earlier
+// fact of the same kind.
+func (f *Facts) ExportPackageFact(pkg *types.Package, fact interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.facts[factKey{pkg.Path(), "", factKind(fact)}] = fact
+}
+
+// ImportPackageFact copies the fact about pkg of fact's kind into fact
+// and reports whether there was such a fact.
+func (f *Facts) ImportPackageFact(pkg *types.Package, fact interface{}) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.get(factKey{pkg.Path(), "", factKind(fact)}, fact)
+}
+
+// Encode encodes all facts, including decoded ones, in a form
+// understood by Decode.
+func (f *Facts) Encode() ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]encodedFact, 0, len(f.facts))
+	for key, fact := range f.facts {
+		raw, ok := fact.(json.RawMessage)
+		if !ok {
+			var err error
+			raw, err = json.Marshal(fact)
+			if err != nil {
+				return nil, fmt.Errorf("couldn't encode fact %s about %s %s: %s", key.kind, key.pkg, key.obj, err)
+			}
+		}
+		out = append(out, encodedFact{key.pkg, key.obj, key.kind, raw})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		if a.Object != b.Object {
+			return a.Object < b.Object
+		}
+		return a.Kind < b.Kind
+	})
+	return json.Marshal(out)
+}
+
+// Decode adds the facts encoded in data, as returned by Encode.
+// Facts already present take precedence.
+func (f *Facts) Decode(data []byte) error {
+	var in []encodedFact
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, fact := range in {
+		key := factKey{fact.Package, fact.Object, fact.Kind}
+		if _, ok := f.facts[key]; !ok {
+			f.facts[key] = fact.Fact
+		}
+	}
+	return nil
+}
+
+// packageObjects calls fn for all package-level objects of pkg, the
+// methods of its defined types and the fields of its types and
+// variables, with paths that identify them within pkg, such as
+// "Reader", "Reader.Read" and "Config.Timeout".
+func packageObjects(pkg *types.Package, fn func(path string, obj types.Object)) {
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		fn(name, obj)
+		switch obj := obj.(type) {
+		case *types.Var:
+			// fields of anonymous struct types, as in
+			// var Config struct{ Timeout int }
+			fieldObjects(name, obj.Type(), fn)
+		case *types.TypeName:
+			if obj.IsAlias() {
+				continue
+			}
+			if named, ok := obj.Type().(*types.Named); ok {
+				for i := 0; i < named.NumMethods(); i++ {
+					m := named.Method(i)
+					fn(name+"."+m.Name(), m)
+				}
+			}
+			if T, ok := obj.Type().Underlying().(*types.Interface); ok {
+				for i := 0; i < T.NumExplicitMethods(); i++ {
+					m := T.ExplicitMethod(i)
+					fn(name+"."+m.Name(), m)
+				}
+				continue
+			}
+			fieldObjects(name, obj.Type().Underlying(), fn)
+		}
+	}
+}
+
+// fieldObjects calls fn for the fields of the struct types that the
+// unnamed type T is composed of, with paths below path. Fields of
+// anonymous struct types nested in other structs have paths such as
+// "T.Options.Verbose".
+func fieldObjects(path string, T types.Type, fn func(path string, obj types.Object)) {
+	switch T := T.(type) {
+	case *types.Struct:
+		for i := 0; i < T.NumFields(); i++ {
+			f := T.Field(i)
+			fn(path+"."+f.Name(), f)
+			fieldObjects(path+"."+f.Name(), f.Type(), fn)
+		}
+	case *types.Pointer:
+		fieldObjects(path, T.Elem(), fn)
+	case *types.Slice:
+		fieldObjects(path, T.Elem(), fn)
+	case *types.Array:
+		fieldObjects(path, T.Elem(), fn)
+	case *types.Map:
+		fieldObjects(path, T.Elem(), fn)
+	case *types.Chan:
+		fieldObjects(path, T.Elem(), fn)
+	}
+}
@@ -0,0 +1,103 @@
+package lint
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+type testFact struct {
+	Text string
+}
+
+func checkSource(t *testing.T, src string) *types.Package {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "a.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := (&types.Config{}).Check("example.com/a", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkg
+}
+
+// configTimeout returns the field Config.Nested[i].Timeout of pkg.
+func configTimeout(pkg *types.Package) types.Object {
+	config := pkg.Scope().Lookup("Config").Type().(*types.Struct)
+	nested := config.Field(0).Type().(*types.Slice).Elem().(*types.Struct)
+	return nested.Field(0)
+}
+
+func TestFactsRoundTrip(t *testing.T) {
+	const src = `package a
+
+type T struct{ F int }
+
+var Config struct{ Nested []struct{ Timeout int } }
+
+func (T) M() {}
+
+func Fn() {
+	type local struct{ G int }
+}
+`
+	pkg := checkSource(t, src)
+	T := pkg.Scope().Lookup("T").(*types.TypeName)
+	field := T.Type().Underlying().(*types.Struct).Field(0)
+	method := T.Type().(*types.Named).Method(0)
+	timeout := configTimeout(pkg)
+
+	facts := NewFacts()
+	facts.ExportPackageFact(pkg, &testFact{"package"})
+	facts.ExportObjectFact(field, &testFact{"field"})
+	facts.ExportObjectFact(method, &testFact{"method"})
+	facts.ExportObjectFact(timeout, &testFact{"nested field"})
+	facts.ExportObjectFact(types.NewVar(token.NoPos, pkg, "x", types.Typ[types.Int]), &testFact{"unreachable"})
+
+	data, err := facts.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Decode into facts about a different instance of the package,
+	// as when it is loaded from export data.
+	pkg = checkSource(t, src)
+	T = pkg.Scope().Lookup("T").(*types.TypeName)
+	field = T.Type().Underlying().(*types.Struct).Field(0)
+	method = T.Type().(*types.Named).Method(0)
+	timeout = configTimeout(pkg)
+	decoded := NewFacts()
+	if err := decoded.Decode(data); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		get  func(fact *testFact) bool
+		want string
+	}{
+		{func(fact *testFact) bool { return decoded.ImportPackageFact(pkg, fact) }, "package"},
+		{func(fact *testFact) bool { return decoded.ImportObjectFact(field, fact) }, "field"},
+		{func(fact *testFact) bool { return decoded.ImportObjectFact(method, fact) }, "method"},
+		// the second import uses the cached fact
+		{func(fact *testFact) bool { return decoded.ImportObjectFact(method, fact) }, "method"},
+		{func(fact *testFact) bool { return decoded.ImportObjectFact(timeout, fact) }, "nested field"},
+		{func(fact *testFact) bool { return decoded.ImportObjectFact(T, fact) }, ""},
+	} {
+		var fact testFact
+		ok := tt.get(&fact)
+		if ok != (tt.want != "") || fact.Text != tt.want {
+			t.Errorf("got %q, %t, want %q", fact.Text, ok, tt.want)
+		}
+	}
+
+	again, err := decoded.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(data) {
+		t.Errorf("facts changed after decoding:\n%s\n%s", data, again)
+	}
+}
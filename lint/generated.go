@@ -5,6 +5,10 @@ import (
 	"bytes"
 	"io"
 	"os"
+	"path/filepath"
+	"regexp"
+
+	"honnef.co/go/tools/config"
 )
 
 var (
@@ -16,13 +20,48 @@ var (
 	crnl   = []byte("\r\n")
 )
 
-func isGenerated(path string) bool {
+// generatedMatcher determines whether files are generated, based on
+// the standard header, configured header patterns and configured
+// file name patterns.
+type generatedMatcher struct {
+	files   []string
+	headers []*regexp.Regexp
+}
+
+func newGeneratedMatcher(cfg config.Config) *generatedMatcher {
+	m := &generatedMatcher{files: cfg.GeneratedFiles}
+	for _, h := range cfg.GeneratedHeaders {
+		// config.Load has already validated the patterns
+		if re, err := regexp.Compile(h); err == nil {
+			m.headers = append(m.headers, re)
+		}
+	}
+	return m
+}
+
+// isGenerated reports whether the file at path is generated,
+// preferring the file's contents in overlay over those on disk.
+func (m *generatedMatcher) isGenerated(path string, overlay map[string][]byte) bool {
+	base := filepath.Base(path)
+	for _, pattern := range m.files {
+		if ok, _ := filepath.Match(pattern, base); ok {
+			return true
+		}
+	}
+
+	if b, ok := overlay[path]; ok {
+		return isGenerated(bytes.NewReader(b), m.headers)
+	}
 	f, err := os.Open(path)
 	if err != nil {
 		return false
 	}
 	defer f.Close()
-	br := bufio.NewReader(f)
+	return isGenerated(f, m.headers)
+}
+
+func isGenerated(r io.Reader, headers []*regexp.Regexp) bool {
+	br := bufio.NewReader(r)
 	for {
 		s, err := br.ReadBytes('\n')
 		if err != nil && err != io.EOF {
@@ -36,6 +75,11 @@ func isGenerated(path string) bool {
 		if bytes.Equal(s, oldCgo) {
 			return true
 		}
+		for _, re := range headers {
+			if re.Match(s) {
+				return true
+			}
+		}
 		if err == io.EOF {
 			break
 		}
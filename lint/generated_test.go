@@ -0,0 +1,136 @@
+package lint
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+	"honnef.co/go/tools/config"
+)
+
+func TestGeneratedMatcher(t *testing.T) {
+	m := newGeneratedMatcher(config.Config{
+		GeneratedFiles:   []string{"*.pb.go", "mock_*.go"},
+		GeneratedHeaders: []string{`^// Autogenerated by \w+$`},
+	})
+	overlay := map[string][]byte{
+		"/a/std.go":     []byte("// Code generated by foo. DO NOT EDIT.\n\npackage a\n"),
+		"/a/cgo.go":     []byte("// Created by cgo - DO NOT EDIT\r\n\npackage a\n"),
+		"/a/custom.go":  []byte("package a\n\n// Autogenerated by bar\n"),
+		"/a/foo.pb.go":  []byte("package a\n"),
+		"/a/mock_x.go":  []byte("package a\n"),
+		"/a/normal.go":  []byte("// Code generated by hand, please edit.\npackage a\n"),
+		"/a/nomatch.go": []byte("package a\n\n// Autogenerated by bar, sort of\n"),
+	}
+	tests := map[string]bool{
+		"/a/std.go":     true,
+		"/a/cgo.go":     true,
+		"/a/custom.go":  true,
+		"/a/foo.pb.go":  true,
+		"/a/mock_x.go":  true,
+		"/a/normal.go":  false,
+		"/a/nomatch.go": false,
+	}
+	for path, want := range tests {
+		if got := m.isGenerated(path, overlay); got != want {
+			t.Errorf("isGenerated(%q) = %t, want %t", path, got, want)
+		}
+	}
+}
+
+// generatedChecker records which files of the linted package are
+// generated.
+type generatedChecker struct {
+	mu        sync.Mutex
+	generated map[string]bool
+}
+
+func (*generatedChecker) Name() string   { return "generated" }
+func (*generatedChecker) Prefix() string { return "TEST" }
+func (*generatedChecker) Init(*Program)  {}
+func (c *generatedChecker) Checks() []Check {
+	return []Check{{ID: "TEST1000", Needs: NeedsTypes, Fn: func(j *Job) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for f, gen := range j.Pkg.Generated {
+			c.generated[filepath.Base(j.Pkg.Fset.File(f.Pos()).Name())] = gen
+		}
+	}}}
+}
+
+func TestGeneratedDefaults(t *testing.T) {
+	dir, err := ioutil.TempDir("", "staticcheck-generated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// The config of the package's directory determines the
+	// patterns; stop at it.
+	if err := ioutil.WriteFile(filepath.Join(dir, "staticcheck.conf"), []byte("root = true\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]bool{
+		"a.pb.go":     true,
+		"a.pb.gw.go":  true,
+		"a_string.go": true,
+		"mock_a.go":   true,
+		"a_mock.go":   true,
+		"y.go":        true,
+		"a.go":        false,
+		"mock.go":     false,
+	}
+	fset := token.NewFileSet()
+	var (
+		files []*ast.File
+		names []string
+	)
+	for name := range want {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte("package a\n"), 0666); err != nil {
+			t.Fatal(err)
+		}
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, f)
+		names = append(names, path)
+	}
+	info := &types.Info{
+		Types: map[ast.Expr]types.TypeAndValue{},
+		Defs:  map[*ast.Ident]types.Object{},
+		Uses:  map[*ast.Ident]types.Object{},
+	}
+	tpkg, err := (&types.Config{}).Check("a", fset, files, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := &packages.Package{
+		ID:        "a",
+		Name:      "a",
+		PkgPath:   "a",
+		GoFiles:   names,
+		Fset:      fset,
+		Syntax:    files,
+		Types:     tpkg,
+		TypesInfo: info,
+		Imports:   map[string]*packages.Package{},
+	}
+
+	c := &generatedChecker{generated: map[string]bool{}}
+	l := &Linter{Checkers: []Checker{c}}
+	l.Lint([]*packages.Package{pkg}, nil)
+	for name, gen := range want {
+		if got, ok := c.generated[name]; !ok || got != gen {
+			t.Errorf("%s: got generated = %t, want %t", name, got, gen)
+		}
+	}
+}
@@ -3,8 +3,11 @@ package lint // import "honnef.co/go/tools/lint"
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"go/ast"
+	"go/build"
 	"go/token"
 	"go/types"
 	"io"
@@ -12,6 +15,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -27,11 +31,37 @@ import (
 type Job struct {
 	Pkg       *Pkg
 	GoVersion int
+	// Facts holds the facts of the package and its dependencies.
+	Facts *Facts
 
 	check    Check
 	problems []Problem
+	ctx      context.Context
 
 	duration time.Duration
+	// abandoned is set if the job gave up because it ran out of
+	// time.
+	abandoned bool
+}
+
+// Context returns the job's context, which is done once the job has
+// exceeded its time budget. Expensive checks should call Abandon and
+// return early when it is done. A job that takes too long is
+// abandoned either way, but returning early frees up resources for
+// other jobs.
+func (j *Job) Context() context.Context {
+	if j.ctx == nil {
+		return context.Background()
+	}
+	return j.ctx
+}
+
+// Abandon marks the job as having run out of time. Checks call it
+// when they return early because their context is done, or when
+// they did their expensive work elsewhere, such as in their
+// checker's Init, and couldn't finish it within the budget.
+func (j *Job) Abandon() {
+	j.abandoned = true
 }
 
 type Ignore interface {
@@ -113,6 +143,14 @@ type Program struct {
 	InitialPackages []*Pkg
 	AllPackages     []*packages.Package
 	AllFunctions    []*ssa.Function
+	// Facts holds the facts of all packages.
+	Facts *Facts
+
+	// JobTimeout is the time budget of each job, or zero for no
+	// limit. Checkers that do per-package work in Init should apply
+	// it to that work and abandon the jobs of packages that exceed
+	// it.
+	JobTimeout time.Duration
 }
 
 func (prog *Program) Fset() *token.FileSet {
@@ -132,10 +170,42 @@ const (
 // Problem represents a problem in some source code.
 type Problem struct {
 	Position token.Position // position in source file
+	End      token.Position // end of the offending range, if known
 	Text     string         // the prose that describes the problem
 	Check    string
 	Package  *Pkg
 	Severity Severity
+
+	// Configurations lists the build configurations the problem
+	// occurred in, when linting multiple configurations.
+	Configurations []string
+	// Variants lists the IDs of the package variants the problem
+	// occurred in, such as "example.com/pkg" and
+	// "example.com/pkg [example.com/pkg.test]" for a file that is
+	// part of both a package and its test variant.
+	Variants []string
+	// Owners lists the owners of the problem's file, according to a
+	// CODEOWNERS file.
+	Owners []string
+	// Related lists other positions that help explain the problem.
+	Related []RelatedInformation
+	// Edits, if not empty, fix the problem when applied together.
+	Edits []TextEdit
+}
+
+// A TextEdit replaces the source between Position and End with
+// NewText. Its positions refer to the file on disk, ignoring //line
+// directives, and their offsets are valid.
+type TextEdit struct {
+	Position token.Position
+	End      token.Position
+	NewText  string
+}
+
+// RelatedInformation describes a position related to a problem.
+type RelatedInformation struct {
+	Position token.Position
+	Message  string
 }
 
 func (p *Problem) String() string {
@@ -148,27 +218,92 @@ func (p *Problem) String() string {
 type Checker interface {
 	Name() string
 	Prefix() string
+	// Init is called once the SSA form of the whole program has been
+	// built.
 	Init(*Program)
 	Checks() []Check
 }
 
+// A FunctionPreparer is a Checker that transforms the SSA form of
+// functions before any check looks at them, for example to simplify
+// their control flow. PrepareFunction is called for each function
+// with a body once its package has been built. It is called
+// concurrently for functions of different packages, and before
+// Init.
+type FunctionPreparer interface {
+	Checker
+	PrepareFunction(fn *ssa.Function)
+}
+
 type Check struct {
 	Fn              Func
 	ID              string
 	FilterGenerated bool
 	Doc             string
+	// Needs describes what the check needs before it can run on a
+	// package. Checks that need less can start earlier.
+	Needs Need
 }
 
+// Need describes what a check needs before it can run on a package.
+type Need int
+
+const (
+	// NeedsProgram is the default, for checks that need the SSA form
+	// of the whole program or the state of their checker, which is
+	// computed by Init.
+	NeedsProgram Need = iota
+	// NeedsSSA is for checks that need the SSA form and facts of the
+	// package and its dependencies, but nothing computed by Init.
+	// They start as soon as the package and its dependencies have
+	// been built and their facts computed.
+	NeedsSSA
+	// NeedsTypes is for checks that only need syntax and type
+	// information. They start right away, while SSA form is being
+	// built.
+	NeedsTypes
+)
+
 // A Linter lints Go source code.
 type Linter struct {
-	Checkers      []Checker
-	Ignores       []Ignore
+	Checkers []Checker
+	Ignores  []Ignore
+	// GoVersion is the targeted minor version of Go. If it is
+	// negative, each package targets the version configured in its
+	// staticcheck.conf, the version declared in its module's go.mod
+	// or the version of the Go toolchain, in that order.
 	GoVersion     int
 	ReturnIgnored bool
 	Config        config.Config
 
+	// MaxConcurrentJobs is the number of jobs to run concurrently,
+	// or GOMAXPROCS if it is zero.
 	MaxConcurrentJobs int
 	PrintStats        bool
+	// Facts, if not nil, holds facts about dependencies that weren't
+	// loaded from source, such as facts decoded from a previous run.
+	// Lint adds the facts of all packages loaded from source to it.
+	Facts *Facts
+	// FactsOnly skips running jobs, for when only the facts of the
+	// linted packages are of interest. Lint returns no problems.
+	FactsOnly bool
+
+	// JobTimeout is the time budget of each job, that is of each
+	// check on each package. Jobs that exceed it are abandoned and
+	// reported as warnings. Zero means no limit. It only limits how
+	// long Lint waits for a job: a check that doesn't stop when its
+	// context is done keeps running in the background, outside of
+	// MaxConcurrentJobs.
+	JobTimeout time.Duration
+
+	// Overlay maps absolute file names to contents that replace the
+	// files on disk. It must match the overlay that was used for
+	// loading the packages.
+	Overlay map[string][]byte
+	// ConfigRootMarkers names files, such as go.mod, whose presence
+	// in a directory stops the inheritance of configuration from
+	// parent directories.
+	ConfigRootMarkers []string
 
 	automaticIgnores []Ignore
 }
@@ -198,6 +333,58 @@ func (l *Linter) ignore(p Problem) bool {
 	return false
 }
 
+func (l *Linter) goVersion(pkg *packages.Package, cfg config.Config) int {
+	if l.GoVersion >= 0 {
+		return l.GoVersion
+	}
+	if cfg.GoVersion != "" {
+		if v, err := ParseGoVersion(cfg.GoVersion); err == nil {
+			return v
+		}
+	}
+	if pkg.Module != nil && pkg.Module.GoVersion != "" {
+		if v, err := ParseGoVersion(pkg.Module.GoVersion); err == nil {
+			return v
+		}
+	}
+	return DefaultGoVersion()
+}
+
+// DefaultGoVersion returns the minor version of the Go toolchain
+// that was used to build the program.
+func DefaultGoVersion() int {
+	tags := build.Default.ReleaseTags
+	v, err := ParseGoVersion(tags[len(tags)-1][2:])
+	if err != nil {
+		panic(fmt.Sprintf("internal error: %s", err))
+	}
+	return v
+}
+
+// ParseGoVersion parses a Go version in the format 1.x or 1.x.y,
+// optionally followed by a pre-release suffix such as rc1 or beta2,
+// and returns its minor version x.
+func ParseGoVersion(s string) (int, error) {
+	if !strings.HasPrefix(s, "1.") {
+		return 0, errors.New("invalid Go version")
+	}
+	s = s[2:]
+	i := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+	if i == -1 {
+		i = len(s)
+	}
+	rest := s[i:]
+	if !(rest == "" || strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, "rc") ||
+		strings.HasPrefix(rest, "beta") || strings.HasPrefix(rest, "alpha")) {
+		return 0, errors.New("invalid Go version")
+	}
+	v, err := strconv.Atoi(s[:i])
+	if err != nil {
+		return 0, errors.New("invalid Go version")
+	}
+	return v, nil
+}
+
 func (j *Job) File(node Positioner) *ast.File {
 	return j.Pkg.tokenFileMap[j.Pkg.Fset.File(node.Pos())]
 }
@@ -220,8 +407,10 @@ type PerfStats struct {
 }
 
 type JobStat struct {
-	Job      string
-	Duration time.Duration
+	Job       string
+	Package   string
+	Duration  time.Duration
+	Abandoned bool
 }
 
 func (stats *PerfStats) Print(w io.Writer) {
@@ -235,30 +424,35 @@ func (stats *PerfStats) Print(w io.Writer) {
 	}
 	fmt.Fprintln(w)
 
-	fmt.Fprintln(w, "Jobs:")
+	fmt.Fprintln(w, "Jobs, slowest first:")
 	sort.Slice(stats.Jobs, func(i, j int) bool {
-		return stats.Jobs[i].Duration < stats.Jobs[j].Duration
+		return stats.Jobs[i].Duration > stats.Jobs[j].Duration
 	})
 	var total time.Duration
+	abandoned := 0
 	for _, job := range stats.Jobs {
-		fmt.Fprintf(w, "\t%s: %s\n", job.Job, job.Duration)
+		note := ""
+		if job.Abandoned {
+			note = " (abandoned)"
+			abandoned++
+		}
+		fmt.Fprintf(w, "\t%s %s: %s%s\n", job.Job, job.Package, job.Duration, note)
 		total += job.Duration
 	}
 	fmt.Fprintf(w, "\tTotal: %s\n", total)
+	if abandoned > 0 {
+		fmt.Fprintf(w, "\tAbandoned: %d\n", abandoned)
+	}
 }
 
 func (l *Linter) Lint(initial []*packages.Package, stats *PerfStats) []Problem {
 	allPkgs := allPackages(initial)
 	t := time.Now()
+	// Packages are only created here. They are built further down,
+	// while checks that don't need SSA form are already running.
 	ssaprog, _ := ssautil.Packages(allPkgs, ssa.GlobalDebug)
-	ssaprog.Build()
-	if stats != nil {
-		stats.SSABuild = time.Since(t)
-	}
-	runtime.GC()
 
-	t = time.Now()
-	pkgMap := map[*ssa.Package]*Pkg{}
+	pkgMap := map[*packages.Package]*Pkg{}
 	var pkgs []*Pkg
 	for _, pkg := range initial {
 		ssapkg := ssaprog.Package(pkg.Types)
@@ -272,7 +466,7 @@ func (l *Linter) Lint(initial []*packages.Package, stats *PerfStats) []Problem {
 			// a/b/c/d, we'll process a, a/b, a/b/c, a, a/b, a/b/c,
 			// a/b/c/d – we should cache configs per package and only
 			// load the new levels.
-			cfg, err = config.Load(dir)
+			cfg, err = config.Load(dir, l.ConfigRootMarkers...)
 			if err != nil {
 				// FIXME(dh): we couldn't load the config, what are we
 				// supposed to do? probably tell the user somehow
@@ -284,35 +478,33 @@ func (l *Linter) Lint(initial []*packages.Package, stats *PerfStats) []Problem {
 			SSA:          ssapkg,
 			Package:      pkg,
 			Config:       cfg,
-			Generated:    map[string]bool{},
+			GoVersion:    l.goVersion(pkg, cfg),
+			Generated:    map[*ast.File]bool{},
 			tokenFileMap: map[*token.File]*ast.File{},
 		}
 		pkg.Inspector = inspector.New(pkg.Syntax)
+		gen := newGeneratedMatcher(cfg)
 		for _, f := range pkg.Syntax {
 			tf := pkg.Fset.File(f.Pos())
 			pkg.tokenFileMap[tf] = f
 
 			path := DisplayPosition(pkg.Fset, f.Pos()).Filename
-			pkg.Generated[path] = isGenerated(path)
+			pkg.Generated[f] = gen.isGenerated(path, l.Overlay)
 		}
-		pkgMap[ssapkg] = pkg
+		pkgMap[pkg.Package] = pkg
 		pkgs = append(pkgs, pkg)
 	}
 
+	facts := l.Facts
+	if facts == nil {
+		facts = NewFacts()
+	}
 	prog := &Program{
 		SSA:             ssaprog,
 		InitialPackages: pkgs,
 		AllPackages:     allPkgs,
-	}
-
-	for fn := range ssautil.AllFunctions(ssaprog) {
-		prog.AllFunctions = append(prog.AllFunctions, fn)
-		if fn.Pkg == nil {
-			continue
-		}
-		if pkg, ok := pkgMap[fn.Pkg]; ok {
-			pkg.InitialFunctions = append(pkg.InitialFunctions, fn)
-		}
+		Facts:           facts,
+		JobTimeout:      l.JobTimeout,
 	}
 
 	var out []Problem
@@ -385,47 +577,89 @@ func (l *Linter) Lint(initial []*packages.Package, stats *PerfStats) []Problem {
 		stats.OtherInitWork = time.Since(t)
 	}
 
-	for _, checker := range l.Checkers {
-		t := time.Now()
-		checker.Init(prog)
-		if stats != nil {
-			stats.CheckerInits[checker.Name()] = time.Since(t)
-		}
+	// Jobs start as soon as what their checks need is available:
+	// checks that only need types start right away, checks that need
+	// SSA form once their package and its dependencies have been
+	// built, and all other checks once the whole program has been
+	// built and the checkers have been initialized.
+	var (
+		jobs        []*Job
+		allChecks   []string
+		wg          sync.WaitGroup
+		ready       = map[*packages.Package]chan struct{}{}
+		initialized = make(chan struct{})
+	)
+	for _, pkg := range allPkgs {
+		ready[pkg] = make(chan struct{})
 	}
-
-	var jobs []*Job
-	var allChecks []string
-
-	var wg sync.WaitGroup
+	maxJobs := l.MaxConcurrentJobs
+	if maxJobs <= 0 {
+		maxJobs = runtime.GOMAXPROCS(0)
+	}
+	sem := make(chan struct{}, maxJobs)
 	for _, checker := range l.Checkers {
 		for _, check := range checker.Checks() {
 			allChecks = append(allChecks, check.ID)
-			if check.Fn == nil {
+			if check.Fn == nil || l.FactsOnly {
 				continue
 			}
 			for _, pkg := range pkgs {
+				var start chan struct{}
+				switch check.Needs {
+				case NeedsTypes:
+				case NeedsSSA:
+					start = ready[pkg.Package]
+				default:
+					start = initialized
+				}
 				j := &Job{
 					Pkg:       pkg,
+					Facts:     facts,
 					check:     check,
-					GoVersion: l.GoVersion,
+					GoVersion: pkg.GoVersion,
 				}
 				jobs = append(jobs, j)
 				wg.Add(1)
-				go func(check Check, j *Job) {
-					t := time.Now()
-					check.Fn(j)
-					j.duration = time.Since(t)
-					wg.Done()
-				}(check, j)
+				go func(check Check, j *Job, start chan struct{}) {
+					defer wg.Done()
+					if start != nil {
+						<-start
+					}
+					sem <- struct{}{}
+					defer func() { <-sem }()
+					l.runJob(check, j)
+				}(check, j, start)
 			}
 		}
 	}
 
+	t = time.Now()
+	l.buildPackages(ssaprog, allPkgs, pkgMap, facts, ready)
+	if stats != nil {
+		stats.SSABuild = time.Since(t)
+	}
+	runtime.GC()
+	for fn := range ssautil.AllFunctions(ssaprog) {
+		prog.AllFunctions = append(prog.AllFunctions, fn)
+	}
+
+	for _, checker := range l.Checkers {
+		t := time.Now()
+		checker.Init(prog)
+		if stats != nil {
+			stats.CheckerInits[checker.Name()] = time.Since(t)
+		}
+	}
+	close(initialized)
+	if l.FactsOnly {
+		return nil
+	}
+
 	wg.Wait()
 
 	for _, j := range jobs {
 		if stats != nil {
-			stats.Jobs = append(stats.Jobs, JobStat{j.check.ID, j.duration})
+			stats.Jobs = append(stats.Jobs, JobStat{j.check.ID, j.Pkg.PkgPath, j.duration, j.abandoned})
 		}
 		for _, p := range j.problems {
 			if p.Package == nil {
@@ -444,6 +678,7 @@ func (l *Linter) Lint(initial []*packages.Package, stats *PerfStats) []Problem {
 			// analysis, which shouldn't run at all. It may be easiest
 			// to implement this in the individual checks.
 			if (l.ReturnIgnored || p.Severity != Ignored) && allowedChecks[p.Check] {
+				p.Variants = []string{p.Package.ID}
 				out = append(out, p)
 			}
 		}
@@ -502,30 +737,74 @@ func (l *Linter) Lint(initial []*packages.Package, stats *PerfStats) []Problem {
 		if pi.Column != pj.Column {
 			return pi.Column < pj.Column
 		}
-
-		return out[i].Text < out[j].Text
+		if out[i].Check != out[j].Check {
+			return out[i].Check < out[j].Check
+		}
+		if out[i].Text != out[j].Text {
+			return out[i].Text < out[j].Text
+		}
+		return variant(out[i]) < variant(out[j])
 	})
 
 	if l.PrintStats && stats != nil {
 		stats.Print(os.Stderr)
 	}
 
-	if len(out) < 2 {
-		return out
+	return mergeVariants(out)
+}
+
+func variant(p Problem) string {
+	if len(p.Variants) == 0 {
+		return ""
 	}
+	return p.Variants[0]
+}
 
-	uniq := make([]Problem, 0, len(out))
-	uniq = append(uniq, out[0])
-	prev := out[0]
-	for _, p := range out[1:] {
-		if prev.Position == p.Position && prev.Text == p.Text {
-			continue
+// mergeVariants merges identical problems that were reported by the
+// same check at the same position in different variants of a package,
+// such as a package and its test variant, which share files. The
+// merged problem lists all of the variants. Problems whose texts differ
+// are kept apart, each listing its own variants. Problems must be
+// sorted by position and check.
+func mergeVariants(ps []Problem) []Problem {
+	if len(ps) < 2 {
+		return ps
+	}
+	out := make([]Problem, 0, len(ps))
+	// group is the index of the first problem in out that has the
+	// same position and check as the current problem.
+	group := 0
+	for _, p := range ps {
+		if len(out) > 0 && (out[group].Position != p.Position || out[group].Check != p.Check) {
+			group = len(out)
+		}
+		merged := false
+		for i := group; i < len(out); i++ {
+			q := &out[i]
+			if q.Text == p.Text {
+				for _, v := range p.Variants {
+					if !hasVariant(q.Variants, v) {
+						q.Variants = append(q.Variants, v)
+					}
+				}
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			out = append(out, p)
 		}
-		prev = p
-		uniq = append(uniq, p)
 	}
+	return out
+}
 
-	return uniq
+func hasVariant(variants []string, v string) bool {
+	for _, w := range variants {
+		if w == v {
+			return true
+		}
+	}
+	return false
 }
 
 func FilterChecks(allChecks []string, checks []string) map[string]bool {
@@ -576,10 +855,13 @@ type Pkg struct {
 	SSA              *ssa.Package
 	InitialFunctions []*ssa.Function
 	*packages.Package
-	Config    config.Config
+	Config config.Config
+	// GoVersion is the minor version of Go that the package
+	// targets.
+	GoVersion int
 	Inspector *inspector.Inspector
-	// TODO(dh): this map should probably map from *ast.File, not string
-	Generated map[string]bool
+	// Generated records which of the package's files are generated.
+	Generated map[*ast.File]bool
 
 	tokenFileMap map[*token.File]*ast.File
 }
@@ -604,11 +886,17 @@ func DisplayPosition(fset *token.FileSet, p token.Pos) token.Position {
 
 func (j *Job) Errorf(n Positioner, format string, args ...interface{}) *Problem {
 	pos := DisplayPosition(j.Pkg.Fset, n.Pos())
-	if j.Pkg.Generated[pos.Filename] && j.check.FilterGenerated {
+	if j.Pkg.Generated[j.File(n)] &&
+		(j.check.FilterGenerated || j.Pkg.Config.GeneratedCode == config.GeneratedCodeExclude) {
 		return nil
 	}
+	var end token.Position
+	if n, ok := n.(interface{ End() token.Pos }); ok {
+		end = DisplayPosition(j.Pkg.Fset, n.End())
+	}
 	problem := Problem{
 		Position: pos,
+		End:      end,
 		Text:     fmt.Sprintf(format, args...),
 		Check:    j.check.ID,
 		Package:  j.Pkg,
@@ -617,6 +905,181 @@ func (j *Job) Errorf(n Positioner, format string, args ...interface{}) *Problem
 	return &j.problems[len(j.problems)-1]
 }
 
+// Edit attaches an edit to p, which must have been returned by
+// Errorf, replacing the source of n with newText. It does nothing if
+// p is nil.
+func (j *Job) Edit(p *Problem, n ast.Node, newText string) {
+	if p == nil {
+		return
+	}
+	p.Edits = append(p.Edits, TextEdit{
+		Position: j.Pkg.Fset.PositionFor(n.Pos(), false),
+		End:      j.Pkg.Fset.PositionFor(n.End(), false),
+		NewText:  newText,
+	})
+}
+
+// Related attaches a related position to p, which must have been
+// returned by Errorf. It does nothing if p is nil.
+func (j *Job) Related(p *Problem, n Positioner, format string, args ...interface{}) {
+	if p == nil {
+		return
+	}
+	p.Related = append(p.Related, RelatedInformation{
+		Position: DisplayPosition(j.Pkg.Fset, n.Pos()),
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// runJob runs a check, abandoning it once it exceeds its time
+// budget.
+func (l *Linter) runJob(check Check, j *Job) {
+	t := time.Now()
+	if l.JobTimeout <= 0 {
+		check.Fn(j)
+		j.duration = time.Since(t)
+		if j.abandoned {
+			j.problems = append(j.problems, l.abandonedProblem(j))
+		}
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.JobTimeout)
+	defer cancel()
+	// The check runs on a copy of the job, because we stop waiting
+	// for it once it exceeds its budget, and it may continue to
+	// report problems.
+	run := *j
+	run.ctx = ctx
+	done := make(chan struct{})
+	go func() {
+		check.Fn(&run)
+		close(done)
+	}()
+	select {
+	case <-done:
+		j.problems = run.problems
+		j.abandoned = run.abandoned
+	case <-ctx.Done():
+		j.abandoned = true
+	}
+	j.duration = time.Since(t)
+	if j.abandoned {
+		j.problems = append(j.problems, l.abandonedProblem(j))
+	}
+}
+
+func (l *Linter) abandonedProblem(j *Job) Problem {
+	var pos token.Position
+	if len(j.Pkg.Syntax) > 0 {
+		pos = DisplayPosition(j.Pkg.Fset, j.Pkg.Syntax[0].Package)
+	}
+	// The code isn't at fault, so this is never an error.
+	return Problem{
+		Position: pos,
+		Text: fmt.Sprintf("check %s was abandoned after exceeding its time budget of %s in package %s, so its results may be incomplete",
+			j.check.ID, l.JobTimeout, j.Pkg.PkgPath),
+		Check:    j.check.ID,
+		Package:  j.Pkg,
+		Severity: Warning,
+	}
+}
+
+// buildPackages builds the SSA form of pkgs concurrently, lets the
+// checkers prepare the functions of each package and computes the
+// facts of each package once those of its dependencies are known.
+// ready[pkg] is closed once pkg and all of its dependencies have been
+// built and their facts computed, at which point the initial
+// functions of the corresponding entry in initial have been set.
+// buildPackages returns once all packages have been built.
+func (l *Linter) buildPackages(ssaprog *ssa.Program, pkgs []*packages.Package, initial map[*packages.Package]*Pkg, facts *Facts, ready map[*packages.Package]chan struct{}) {
+	var (
+		preparers    []FunctionPreparer
+		factCheckers []FactChecker
+	)
+	for _, c := range l.Checkers {
+		if p, ok := c.(FunctionPreparer); ok {
+			preparers = append(preparers, p)
+		}
+		if fc, ok := c.(FactChecker); ok {
+			factCheckers = append(factCheckers, fc)
+		}
+	}
+	var wg sync.WaitGroup
+	for _, pkg := range pkgs {
+		wg.Add(1)
+		go func(pkg *packages.Package) {
+			defer wg.Done()
+			var fns []*ssa.Function
+			if ssapkg := ssaprog.Package(pkg.Types); ssapkg != nil {
+				ssapkg.Build()
+				fns = packageFunctions(ssapkg)
+				for _, fn := range fns {
+					if fn.Blocks == nil {
+						continue
+					}
+					for _, p := range preparers {
+						p.PrepareFunction(fn)
+					}
+				}
+			}
+			if p, ok := initial[pkg]; ok {
+				p.InitialFunctions = fns
+			}
+			for _, imp := range pkg.Imports {
+				<-ready[imp]
+			}
+			if len(pkg.Syntax) > 0 {
+				// Packages loaded from export data have no
+				// function bodies to compute facts from. Their
+				// facts, if any, were passed in.
+				for _, fc := range factCheckers {
+					fc.ComputeFacts(pkg, fns, facts)
+				}
+			}
+			close(ready[pkg])
+		}(pkg)
+	}
+	wg.Wait()
+}
+
+// packageFunctions returns the functions of pkg: its package-level
+// functions, the methods of its types and the anonymous functions
+// they contain.
+func packageFunctions(pkg *ssa.Package) []*ssa.Function {
+	var out []*ssa.Function
+	var add func(fn *ssa.Function)
+	add = func(fn *ssa.Function) {
+		out = append(out, fn)
+		for _, anon := range fn.AnonFuncs {
+			add(anon)
+		}
+	}
+	names := make([]string, 0, len(pkg.Members))
+	for name := range pkg.Members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		switch m := pkg.Members[name].(type) {
+		case *ssa.Function:
+			add(m)
+		case *ssa.Type:
+			named, ok := m.Type().(*types.Named)
+			if !ok || named.Obj() != m.Object() {
+				// not a defined type, or an alias
+				continue
+			}
+			for i := 0; i < named.NumMethods(); i++ {
+				if fn := pkg.Prog.FuncValue(named.Method(i)); fn != nil {
+					add(fn)
+				}
+			}
+		}
+	}
+	return out
+}
+
 func allPackages(pkgs []*packages.Package) []*packages.Package {
 	var out []*packages.Package
 	packages.Visit(
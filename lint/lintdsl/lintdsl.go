@@ -12,6 +12,7 @@ import (
 	"go/types"
 	"strings"
 
+	"golang.org/x/tools/go/ast/astutil"
 	"honnef.co/go/tools/lint"
 	"honnef.co/go/tools/ssa"
 )
@@ -217,7 +218,7 @@ func IsGoVersion(j *lint.Job, minor int) bool {
 }
 
 func CallNameAST(j *lint.Job, call *ast.CallExpr) string {
-	switch fun := call.Fun.(type) {
+	switch fun := astutil.Unparen(call.Fun).(type) {
 	case *ast.SelectorExpr:
 		fn, ok := j.Pkg.TypesInfo.ObjectOf(fun.Sel).(*types.Func)
 		if !ok {
@@ -0,0 +1,182 @@
+package lintutil
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"honnef.co/go/tools/lint"
+)
+
+type ownerRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// CodeOwners maps files in a repository to their owners, as described
+// by a CODEOWNERS file.
+type CodeOwners struct {
+	root  string
+	rules []ownerRule
+}
+
+// findRepoRoot returns the closest directory, starting at dir, that
+// contains a .git file or directory.
+func findRepoRoot(dir string) (string, error) {
+	for d := dir; ; {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			return d, nil
+		}
+		nd := filepath.Dir(d)
+		if nd == d {
+			return "", fmt.Errorf("%s is not in a repository", dir)
+		}
+		d = nd
+	}
+}
+
+// LoadCodeOwners reads the CODEOWNERS file of the repository
+// containing dir. Like on GitHub, the file may be in the root of the
+// repository or in its .github or docs directory.
+func LoadCodeOwners(dir string) (*CodeOwners, error) {
+	root, err := findRepoRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		defer f.Close()
+		rules, err := parseCodeOwners(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err)
+		}
+		return &CodeOwners{root: root, rules: rules}, nil
+	}
+	return nil, fmt.Errorf("no CODEOWNERS file in %s", root)
+}
+
+func parseCodeOwners(r io.Reader) ([]ownerRule, error) {
+	var rules []ownerRule
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if i := strings.Index(line, " #"); i != -1 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		re, err := compileOwnerPattern(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", n, err)
+		}
+		var owners []string
+		if len(fields) > 1 {
+			owners = fields[1:]
+		}
+		rules = append(rules, ownerRule{re, owners})
+	}
+	return rules, sc.Err()
+}
+
+// compileOwnerPattern translates a CODEOWNERS pattern, which follows
+// the rules of gitignore patterns, into a regular expression matching
+// slash-separated paths relative to the repository root.
+func compileOwnerPattern(pattern string) (*regexp.Regexp, error) {
+	p := pattern
+	// Patterns containing a slash other than at their end are
+	// relative to the root, others match at any depth.
+	anchored := strings.Contains(strings.TrimSuffix(p, "/"), "/")
+	p = strings.TrimPrefix(p, "/")
+	dirOnly := strings.HasSuffix(p, "/")
+	p = strings.TrimSuffix(p, "/")
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case p[i] == '*':
+			b.WriteString("[^/]*")
+		case p[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+	switch {
+	case dirOnly:
+		b.WriteString("/.*$")
+	case strings.HasSuffix(p, "/*"):
+		// Unlike in gitignore, docs/* doesn't match files in
+		// subdirectories of docs.
+		b.WriteString("$")
+	default:
+		// A pattern matching a directory matches all files in it.
+		b.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(b.String())
+}
+
+// Owners returns the owners of the file with the absolute name
+// filename. Later rules take precedence over earlier ones. Files
+// outside the repository and files matched by rules without owners
+// have no owners.
+func (co *CodeOwners) Owners(filename string) []string {
+	rel, err := filepath.Rel(co.root, filename)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil
+	}
+	rel = filepath.ToSlash(rel)
+	for i := len(co.rules) - 1; i >= 0; i-- {
+		if co.rules[i].pattern.MatchString(rel) {
+			return co.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// annotate sets the owners of problems.
+func (co *CodeOwners) annotate(ps []lint.Problem) {
+	for i := range ps {
+		if ps[i].Position.Filename != "" {
+			ps[i].Owners = co.Owners(ps[i].Position.Filename)
+		}
+	}
+}
+
+// filterOwners returns the problems owned by any of owners.
+func filterOwners(ps []lint.Problem, owners []string) []lint.Problem {
+	want := map[string]bool{}
+	for _, o := range owners {
+		want[o] = true
+	}
+	out := ps[:0]
+	for _, p := range ps {
+		for _, o := range p.Owners {
+			if want[o] {
+				out = append(out, p)
+				break
+			}
+		}
+	}
+	return out
+}
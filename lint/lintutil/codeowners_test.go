@@ -0,0 +1,46 @@
+package lintutil
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCodeOwners(t *testing.T) {
+	const file = `# comment
+*       @everyone
+*.go    @gophers
+/cmd/   @tools # trailing comment
+docs/*  @writers
+**/testdata @testers
+internal/secret
+`
+	rules, err := parseCodeOwners(strings.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := filepath.FromSlash("/repo")
+	co := &CodeOwners{root: root, rules: rules}
+	tests := []struct {
+		file   string
+		owners []string
+	}{
+		{"README.md", []string{"@everyone"}},
+		{"main.go", []string{"@gophers"}},
+		{"lint/lint.go", []string{"@gophers"}},
+		{"cmd/staticcheck/main.go", []string{"@tools"}},
+		{"pkg/cmd/main.go", []string{"@gophers"}},
+		{"docs/index.md", []string{"@writers"}},
+		{"docs/sub/index.md", []string{"@everyone"}},
+		{"lint/testdata/src/a.go", []string{"@testers"}},
+		{"internal/secret/key.go", nil},
+		{"../elsewhere/x.go", nil},
+	}
+	for _, tt := range tests {
+		got := co.Owners(filepath.Join(root, filepath.FromSlash(tt.file)))
+		if !reflect.DeepEqual(got, tt.owners) {
+			t.Errorf("Owners(%q) = %q, want %q", tt.file, got, tt.owners)
+		}
+	}
+}
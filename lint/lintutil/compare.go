@@ -0,0 +1,95 @@
+package lintutil
+
+import (
+	"sort"
+
+	"honnef.co/go/tools/lint"
+)
+
+// A Comparison describes how the problems of two runs differ.
+type Comparison struct {
+	// Introduced lists problems only found by the new run.
+	Introduced []lint.Problem
+	// Fixed lists problems only found by the old run.
+	Fixed []lint.Problem
+	// Unchanged lists problems found by both runs, as reported by
+	// the new run.
+	Unchanged []lint.Problem
+}
+
+// A CheckDelta is the change in the number of problems reported by a
+// single check.
+type CheckDelta struct {
+	Check    string
+	Old, New int
+}
+
+func problemKey(p lint.Problem) string {
+	// Ignore lines and columns, so that problems don't appear as
+	// fixed and reintroduced when unrelated code moves them.
+	return p.Position.Filename + "\x00" + p.Check + "\x00" + p.Text
+}
+
+// Compare matches the problems of two runs. Problems match if they
+// are in the same file, are reported by the same check and have the
+// same message; their positions are ignored. Identical problems are
+// matched in order, so that a duplicated problem is reported as
+// introduced once.
+func Compare(old, new []lint.Problem) Comparison {
+	var c Comparison
+	counts := map[string]int{}
+	for _, p := range old {
+		counts[problemKey(p)]++
+	}
+	for _, p := range new {
+		k := problemKey(p)
+		if counts[k] > 0 {
+			counts[k]--
+			c.Unchanged = append(c.Unchanged, p)
+		} else {
+			c.Introduced = append(c.Introduced, p)
+		}
+	}
+	for _, p := range old {
+		k := problemKey(p)
+		if counts[k] > 0 {
+			counts[k]--
+			c.Fixed = append(c.Fixed, p)
+		}
+	}
+	return c
+}
+
+// Deltas returns the number of problems per check in the old and new
+// runs, for every check whose count changed, sorted by check.
+func (c Comparison) Deltas() []CheckDelta {
+	deltas := map[string]*CheckDelta{}
+	get := func(check string) *CheckDelta {
+		d, ok := deltas[check]
+		if !ok {
+			d = &CheckDelta{Check: check}
+			deltas[check] = d
+		}
+		return d
+	}
+	for _, p := range c.Unchanged {
+		d := get(p.Check)
+		d.Old++
+		d.New++
+	}
+	for _, p := range c.Introduced {
+		get(p.Check).New++
+	}
+	for _, p := range c.Fixed {
+		get(p.Check).Old++
+	}
+
+	var out []CheckDelta
+	for _, d := range deltas {
+		if d.Old != d.New {
+			out = append(out, *d)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Check < out[j].Check })
+	return out
+}
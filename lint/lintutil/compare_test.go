@@ -0,0 +1,51 @@
+package lintutil
+
+import (
+	"go/token"
+	"reflect"
+	"testing"
+
+	"honnef.co/go/tools/lint"
+)
+
+func TestCompare(t *testing.T) {
+	p := func(file string, line int, check, text string) lint.Problem {
+		return lint.Problem{
+			Position: token.Position{Filename: file, Line: line},
+			Check:    check,
+			Text:     text,
+		}
+	}
+	old := []lint.Problem{
+		p("a.go", 1, "SA4006", "x is never used"),
+		p("a.go", 5, "SA4006", "x is never used"),
+		p("b.go", 3, "S1000", "use plain channel send"),
+	}
+	new := []lint.Problem{
+		// moved by an unrelated edit
+		p("a.go", 10, "SA4006", "x is never used"),
+		p("a.go", 20, "SA4006", "x is never used"),
+		p("a.go", 30, "SA4006", "x is never used"),
+		p("c.go", 1, "ST1005", "error strings should not be capitalized"),
+	}
+
+	c := Compare(old, new)
+	if want := []lint.Problem{new[2], new[3]}; !reflect.DeepEqual(c.Introduced, want) {
+		t.Errorf("introduced: got %v, want %v", c.Introduced, want)
+	}
+	if want := []lint.Problem{old[2]}; !reflect.DeepEqual(c.Fixed, want) {
+		t.Errorf("fixed: got %v, want %v", c.Fixed, want)
+	}
+	if want := []lint.Problem{new[0], new[1]}; !reflect.DeepEqual(c.Unchanged, want) {
+		t.Errorf("unchanged: got %v, want %v", c.Unchanged, want)
+	}
+
+	want := []CheckDelta{
+		{"S1000", 1, 0},
+		{"SA4006", 2, 3},
+		{"ST1005", 0, 1},
+	}
+	if got := c.Deltas(); !reflect.DeepEqual(got, want) {
+		t.Errorf("deltas: got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,140 @@
+package lintutil
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/lint"
+)
+
+// Exit statuses of ProcessFlagSet.
+const (
+	ExitSuccess = 0
+	// ExitLintFailure means that there were more errors or warnings
+	// than the exit policy tolerates.
+	ExitLintFailure = 1
+	// ExitInternalError means that linting couldn't be performed,
+	// for example because of invalid flags or because packages
+	// couldn't be loaded.
+	ExitInternalError = 2
+	// ExitCompileError means that some packages failed to compile.
+	ExitCompileError = 3
+)
+
+// An ExitPolicy determines the exit status based on the reported
+// problems.
+type ExitPolicy struct {
+	// Fail lists the checks whose problems count as errors.
+	// Problems in other checks count as warnings.
+	Fail []string
+	// MaxErrors and MaxWarnings are the numbers of errors and
+	// warnings that are tolerated. Negative values tolerate any
+	// number.
+	MaxErrors   int
+	MaxWarnings int
+	// ChangedFiles, if not nil, restricts the problems that count
+	// towards the exit status to those in the listed files.
+	ChangedFiles map[string]bool
+}
+
+// exitPolicy returns the policy described by the configuration cfg,
+// with the values of explicitly set flags taking precedence.
+func exitPolicy(cfg config.Config, set map[string]bool, fail []string, maxErrors, maxWarnings int, changedSince string) (ExitPolicy, error) {
+	pol := ExitPolicy{
+		Fail:        cfg.Fail,
+		MaxErrors:   *cfg.MaxErrors,
+		MaxWarnings: *cfg.MaxWarnings,
+	}
+	if set["fail"] {
+		pol.Fail = fail
+	}
+	if set["max-errors"] {
+		pol.MaxErrors = maxErrors
+	}
+	if set["max-warnings"] {
+		pol.MaxWarnings = maxWarnings
+	}
+	if !set["fail-changed-since"] {
+		changedSince = cfg.FailChangedSince
+	}
+	if changedSince != "" {
+		files, err := changedFiles(changedSince)
+		if err != nil {
+			return ExitPolicy{}, err
+		}
+		pol.ChangedFiles = files
+	}
+	return pol, nil
+}
+
+// exitStatus returns the exit status for the given problems, whose
+// severities have already been determined.
+func (pol ExitPolicy) exitStatus(ps []lint.Problem) int {
+	var errors, warnings, compile int
+	for _, p := range ps {
+		if p.Check == "compile" && p.Severity != lint.Ignored {
+			// Build failures count regardless of the files they
+			// occur in.
+			compile++
+		}
+		if pol.ChangedFiles != nil && !pol.ChangedFiles[p.Position.Filename] {
+			continue
+		}
+		switch p.Severity {
+		case lint.Error:
+			errors++
+		case lint.Warning:
+			warnings++
+		}
+	}
+	switch {
+	case compile > 0:
+		return ExitCompileError
+	case pol.MaxErrors >= 0 && errors > pol.MaxErrors:
+		return ExitLintFailure
+	case pol.MaxWarnings >= 0 && warnings > pol.MaxWarnings:
+		return ExitLintFailure
+	default:
+		return ExitSuccess
+	}
+}
+
+// changedFiles returns the absolute names of all files that differ
+// between the git revision rev and the working tree, including
+// untracked files.
+func changedFiles(rev string) (map[string]bool, error) {
+	git := func(args ...string) ([]string, error) {
+		cmd := exec.Command("git", args...)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("git %s: %s: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+		}
+		return strings.Split(strings.TrimRight(string(out), "\x00\n"), "\x00"), nil
+	}
+
+	top, err := git("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	diff, err := git("diff", "--name-only", "-z", rev, "--")
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := git("ls-files", "--others", "--exclude-standard", "--full-name", "-z")
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]bool{}
+	for _, name := range append(diff, untracked...) {
+		if name != "" {
+			files[filepath.Join(top[0], filepath.FromSlash(name))] = true
+		}
+	}
+	return files, nil
+}
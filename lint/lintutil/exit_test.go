@@ -0,0 +1,58 @@
+package lintutil
+
+import (
+	"go/token"
+	"testing"
+
+	"honnef.co/go/tools/lint"
+)
+
+func TestExitStatus(t *testing.T) {
+	problem := func(file, check string, sev lint.Severity) lint.Problem {
+		return lint.Problem{Position: token.Position{Filename: file}, Check: check, Severity: sev}
+	}
+	ps := []lint.Problem{
+		problem("/a.go", "SA1000", lint.Error),
+		problem("/a.go", "ST1000", lint.Warning),
+		problem("/b.go", "ST1000", lint.Warning),
+		problem("/b.go", "SA1000", lint.Ignored),
+	}
+
+	var tests = []struct {
+		pol  ExitPolicy
+		ps   []lint.Problem
+		want int
+	}{
+		{ExitPolicy{MaxErrors: 0, MaxWarnings: -1}, ps, ExitLintFailure},
+		{ExitPolicy{MaxErrors: 1, MaxWarnings: -1}, ps, ExitSuccess},
+		{ExitPolicy{MaxErrors: 1, MaxWarnings: 1}, ps, ExitLintFailure},
+		{ExitPolicy{MaxErrors: 1, MaxWarnings: 2}, ps, ExitSuccess},
+		{ExitPolicy{MaxErrors: 0, MaxWarnings: 0, ChangedFiles: map[string]bool{"/c.go": true}}, ps, ExitSuccess},
+		{ExitPolicy{MaxErrors: 0, MaxWarnings: 0, ChangedFiles: map[string]bool{"/b.go": true}}, ps, ExitLintFailure},
+		{ExitPolicy{MaxErrors: -1, MaxWarnings: -1}, append(ps, problem("/c.go", "compile", lint.Error)), ExitCompileError},
+		// build failures outside of the changed files still count
+		{ExitPolicy{MaxErrors: -1, MaxWarnings: -1, ChangedFiles: map[string]bool{"/a.go": true}}, append(ps, problem("/c.go", "compile", lint.Error)), ExitCompileError},
+	}
+	for i, tt := range tests {
+		if got := tt.pol.exitStatus(tt.ps); got != tt.want {
+			t.Errorf("%d: got exit status %d, want %d", i, got, tt.want)
+		}
+	}
+}
+
+func TestExitStatusCompileError(t *testing.T) {
+	ps := []lint.Problem{
+		{Position: token.Position{Filename: "/a.go"}, Check: "compile"},
+		{Position: token.Position{Filename: "/a.go"}, Check: "ST1000"},
+	}
+	// -fail doesn't match compile, which must not turn build
+	// failures into warnings.
+	formatProblems(&recordFormatter{}, ps, []string{"SA1000"})
+	if ps[0].Severity != lint.Error || ps[1].Severity != lint.Warning {
+		t.Fatalf("got severities %d, %d", ps[0].Severity, ps[1].Severity)
+	}
+	pol := ExitPolicy{MaxErrors: -1, MaxWarnings: -1}
+	if got := pol.exitStatus(ps); got != ExitCompileError {
+		t.Errorf("got exit status %d, want %d", got, ExitCompileError)
+	}
+}
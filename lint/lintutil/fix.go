@@ -0,0 +1,292 @@
+package lintutil
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"honnef.co/go/tools/lint"
+	lintformat "honnef.co/go/tools/lint/lintutil/format"
+)
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+)
+
+// fixer applies the edits of problems to files, optionally asking
+// the user about each problem first.
+type fixer struct {
+	// If interactive is set, the user is asked about each problem,
+	// reading answers from in and writing to out.
+	interactive bool
+	in          *bufio.Reader
+	out         io.Writer
+	color       bool
+
+	// src holds the original contents of files, and edits the
+	// accepted edits to them.
+	src   map[string][]byte
+	edits map[string][]lint.TextEdit
+	// acceptAll lists checks whose fixes are accepted without
+	// asking.
+	acceptAll map[string]bool
+	quit      bool
+}
+
+func newFixer(interactive bool, in io.Reader, out io.Writer, color bool) *fixer {
+	return &fixer{
+		interactive: interactive,
+		in:          bufio.NewReader(in),
+		out:         out,
+		color:       color,
+		src:         map[string][]byte{},
+		edits:       map[string][]lint.TextEdit{},
+		acceptAll:   map[string]bool{},
+	}
+}
+
+func (fx *fixer) colorize(code, s string) string {
+	if !fx.color {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+func (fx *fixer) source(filename string) ([]byte, error) {
+	if b, ok := fx.src[filename]; ok {
+		return b, nil
+	}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	fx.src[filename] = b
+	return b, nil
+}
+
+// conflicts reports whether any of edits overlap with previously
+// accepted edits.
+func (fx *fixer) conflicts(edits []lint.TextEdit) bool {
+	for _, e := range edits {
+		for _, prev := range fx.edits[e.Position.Filename] {
+			if e.Position.Offset < prev.End.Offset && prev.Position.Offset < e.End.Offset {
+				return true
+			}
+			if e.Position.Offset == prev.Position.Offset && e.End.Offset == prev.End.Offset {
+				// Two insertions at the same position
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (fx *fixer) accept(edits []lint.TextEdit) {
+	for _, e := range edits {
+		fx.edits[e.Position.Filename] = append(fx.edits[e.Position.Filename], e)
+	}
+}
+
+// fix processes problems and returns those that haven't been fixed or
+// ignored.
+func (fx *fixer) fix(ps []lint.Problem) ([]lint.Problem, error) {
+	var remaining []lint.Problem
+	for _, p := range ps {
+		if len(p.Edits) == 0 || p.Severity == lint.Ignored || fx.quit {
+			remaining = append(remaining, p)
+			continue
+		}
+		for _, e := range p.Edits {
+			if _, err := fx.source(e.Position.Filename); err != nil {
+				return nil, err
+			}
+		}
+		if fx.conflicts(p.Edits) {
+			// The fix will be available again after re-running
+			// staticcheck on the fixed code.
+			remaining = append(remaining, p)
+			continue
+		}
+		if !fx.interactive || fx.acceptAll[p.Check] {
+			fx.accept(p.Edits)
+			continue
+		}
+
+		fx.show(p)
+		edits, err := fx.ask(p)
+		if err != nil {
+			return nil, err
+		}
+		if edits == nil {
+			remaining = append(remaining, p)
+			continue
+		}
+		fx.accept(edits)
+	}
+	return remaining, nil
+}
+
+// show prints p and the diff of its fix.
+func (fx *fixer) show(p lint.Problem) {
+	lintformat.Text{W: fx.out}.Format(p)
+	var files []string
+	byFile := map[string][]lint.TextEdit{}
+	for _, e := range p.Edits {
+		name := e.Position.Filename
+		if _, ok := byFile[name]; !ok {
+			files = append(files, name)
+		}
+		byFile[name] = append(byFile[name], e)
+	}
+	for _, name := range files {
+		if len(files) > 1 {
+			fmt.Fprintln(fx.out, name+":")
+		}
+		old, new := fx.diff(byFile[name])
+		for _, l := range old {
+			fmt.Fprintln(fx.out, fx.colorize(ansiRed, "- "+l))
+		}
+		for _, l := range new {
+			fmt.Fprintln(fx.out, fx.colorize(ansiGreen, "+ "+l))
+		}
+	}
+}
+
+// diff returns the lines affected by edits, which all apply to the
+// same file, before and after applying them.
+func (fx *fixer) diff(edits []lint.TextEdit) (old, new []string) {
+	src := fx.src[edits[0].Position.Filename]
+	start, end := edits[0].Position.Offset, edits[0].End.Offset
+	for _, e := range edits[1:] {
+		if e.Position.Offset < start {
+			start = e.Position.Offset
+		}
+		if e.End.Offset > end {
+			end = e.End.Offset
+		}
+	}
+	start = bytes.LastIndexByte(src[:start], '\n') + 1
+	if i := bytes.IndexByte(src[end:], '\n'); i != -1 {
+		end += i
+	} else {
+		end = len(src)
+	}
+
+	var shifted []lint.TextEdit
+	for _, e := range edits {
+		e.Position.Offset -= start
+		e.End.Offset -= start
+		shifted = append(shifted, e)
+	}
+	before := src[start:end]
+	after := applyEdits(before, shifted)
+	return strings.Split(string(before), "\n"), strings.Split(string(after), "\n")
+}
+
+// ask asks the user what to do about p. It returns the edits to apply,
+// or nil if p should be skipped.
+func (fx *fixer) ask(p lint.Problem) ([]lint.TextEdit, error) {
+	for {
+		fmt.Fprintf(fx.out, "Apply fix? [y]es, [n]o, [a]ll %s fixes, [i]gnore with a directive, [q]uit: ", p.Check)
+		answer, err := fx.readLine()
+		if err == io.EOF {
+			fx.quit = true
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		switch answer {
+		case "y", "yes":
+			return p.Edits, nil
+		case "n", "no", "":
+			return nil, nil
+		case "a", "all":
+			fx.acceptAll[p.Check] = true
+			return p.Edits, nil
+		case "i", "ignore":
+			fmt.Fprint(fx.out, "Reason: ")
+			reason, err := fx.readLine()
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+			if reason == "" {
+				fmt.Fprintln(fx.out, "A reason is required.")
+				continue
+			}
+			return []lint.TextEdit{fx.ignoreDirective(p, reason)}, nil
+		case "q", "quit":
+			fx.quit = true
+			return nil, nil
+		}
+	}
+}
+
+func (fx *fixer) readLine() (string, error) {
+	line, err := fx.in.ReadString('\n')
+	line = strings.TrimSpace(line)
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return line, err
+}
+
+// ignoreDirective returns an edit that inserts a //lint:ignore
+// directive for p on the line above it.
+func (fx *fixer) ignoreDirective(p lint.Problem, reason string) lint.TextEdit {
+	// p.Position may be affected by //line directives, but its
+	// offset and the file name of its edits aren't.
+	pos := p.Edits[0].Position
+	src := fx.src[pos.Filename]
+	off := bytes.LastIndexByte(src[:p.Position.Offset], '\n') + 1
+	indent := src[off:]
+	indent = indent[:len(indent)-len(bytes.TrimLeft(indent, " \t"))]
+	pos.Offset = off
+	return lint.TextEdit{
+		Position: pos,
+		End:      pos,
+		NewText:  fmt.Sprintf("%s//lint:ignore %s %s\n", indent, p.Check, reason),
+	}
+}
+
+// applyEdits applies non-overlapping edits to src.
+func applyEdits(src []byte, edits []lint.TextEdit) []byte {
+	edits = append([]lint.TextEdit(nil), edits...)
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].Position.Offset < edits[j].Position.Offset
+	})
+	var out []byte
+	last := 0
+	for _, e := range edits {
+		out = append(out, src[last:e.Position.Offset]...)
+		out = append(out, e.NewText...)
+		last = e.End.Offset
+	}
+	return append(out, src[last:]...)
+}
+
+// write applies the accepted edits and formats the modified files. It
+// returns the names of the files it wrote.
+func (fx *fixer) write() ([]string, error) {
+	var files []string
+	for name := range fx.edits {
+		files = append(files, name)
+	}
+	sort.Strings(files)
+	for _, name := range files {
+		b := applyEdits(fx.src[name], fx.edits[name])
+		if formatted, err := format.Source(b); err == nil {
+			b = formatted
+		}
+		if err := ioutil.WriteFile(name, b, 0666); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,108 @@
+package lintutil
+
+import (
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"honnef.co/go/tools/lint"
+)
+
+func TestFixer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "staticcheck-fix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "a.go")
+	src := "package a\n\nfunc fn(b bool) bool {\n\tif b == true {\n\t}\n\treturn b != false\n}\n"
+	if err := ioutil.WriteFile(name, []byte(src), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	problem := func(expr, fix string) lint.Problem {
+		off := strings.Index(src, expr)
+		pos := token.Position{Filename: name, Offset: off, Line: strings.Count(src[:off], "\n") + 1}
+		end := pos
+		end.Offset += len(expr)
+		return lint.Problem{
+			Position: pos,
+			Check:    "S1002",
+			Edits:    []lint.TextEdit{{Position: pos, End: end, NewText: fix}},
+		}
+	}
+	ps := []lint.Problem{
+		problem("b == true", "b"),
+		problem("b != false", "b"),
+		// conflicts with the first fix
+		problem("b == true", "!!b"),
+	}
+
+	fx := newFixer(true, strings.NewReader("y\ni\nno reason\n"), ioutil.Discard, false)
+	remaining, err := fx.fix(ps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remaining) != 1 || remaining[0].Edits[0].NewText != "!!b" {
+		t.Errorf("got remaining problems %v", remaining)
+	}
+	if _, err := fx.write(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "package a\n\nfunc fn(b bool) bool {\n\tif b {\n\t}\n\t//lint:ignore S1002 no reason\n\treturn b != false\n}\n"
+	if string(b) != want {
+		t.Errorf("got\n%s\nwant\n%s", b, want)
+	}
+}
+
+func TestFixerMultipleFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "staticcheck-fix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	const src = "package a\n\nvar x = 1\n"
+	names := []string{filepath.Join(dir, "a.go"), filepath.Join(dir, "b.go")}
+	var edits []lint.TextEdit
+	for _, name := range names {
+		if err := ioutil.WriteFile(name, []byte(src), 0666); err != nil {
+			t.Fatal(err)
+		}
+		off := strings.Index(src, "1")
+		pos := token.Position{Filename: name, Offset: off, Line: 3}
+		end := pos
+		end.Offset++
+		edits = append(edits, lint.TextEdit{Position: pos, End: end, NewText: "2"})
+	}
+	ps := []lint.Problem{{Position: edits[0].Position, Check: "S1000", Edits: edits}}
+
+	var out strings.Builder
+	fx := newFixer(true, strings.NewReader("y\n"), &out, false)
+	if _, err := fx.fix(ps); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		if !strings.Contains(out.String(), name+":\n- var x = 1\n+ var x = 2\n") {
+			t.Errorf("diff of %s missing from output:\n%s", name, out.String())
+		}
+	}
+	if _, err := fx.write(); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		b, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "package a\n\nvar x = 2\n"; string(b) != want {
+			t.Errorf("%s: got\n%s\nwant\n%s", name, b, want)
+		}
+	}
+}
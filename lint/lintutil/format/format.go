@@ -8,6 +8,8 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"text/tabwriter"
 
 	"honnef.co/go/tools/lint"
@@ -46,12 +48,38 @@ type Formatter interface {
 	Format(p lint.Problem)
 }
 
+// Multi formats problems with several formatters, for writing
+// multiple outputs in one run. Its Stats method forwards to those
+// formatters that are Statters.
+type Multi []Formatter
+
+func (o Multi) Format(p lint.Problem) {
+	for _, f := range o {
+		f.Format(p)
+	}
+}
+
+func (o Multi) Stats(total, errors, warnings int) {
+	for _, f := range o {
+		if f, ok := f.(Statter); ok {
+			f.Stats(total, errors, warnings)
+		}
+	}
+}
+
 type Text struct {
 	W io.Writer
 }
 
+func configurations(p lint.Problem) string {
+	if len(p.Configurations) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(p.Configurations, " ") + "]"
+}
+
 func (o Text) Format(p lint.Problem) {
-	fmt.Fprintf(o.W, "%v: %s\n", relativePositionString(p.Position), p.String())
+	fmt.Fprintf(o.W, "%v: %s%s\n", relativePositionString(p.Position), p.String(), configurations(p))
 }
 
 type JSON struct {
@@ -70,35 +98,108 @@ func severity(s lint.Severity) string {
 	return ""
 }
 
+type jsonLocation struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+type jsonProblem struct {
+	Code           string        `json:"code"`
+	Severity       string        `json:"severity,omitempty"`
+	Location       jsonLocation  `json:"location"`
+	Message        string        `json:"message"`
+	Configurations []string      `json:"configurations,omitempty"`
+	Variants       []string      `json:"variants,omitempty"`
+	Owners         []string      `json:"owners,omitempty"`
+	Related        []jsonRelated `json:"related,omitempty"`
+}
+
+type jsonRelated struct {
+	Location jsonLocation `json:"location"`
+	Message  string       `json:"message"`
+}
+
 func (o JSON) Format(p lint.Problem) {
-	type location struct {
-		File   string `json:"file"`
-		Line   int    `json:"line"`
-		Column int    `json:"column"`
-	}
-	jp := struct {
-		Code     string   `json:"code"`
-		Severity string   `json:"severity,omitempty"`
-		Location location `json:"location"`
-		Message  string   `json:"message"`
-	}{
+	jp := jsonProblem{
 		Code:     p.Check,
 		Severity: severity(p.Severity),
-		Location: location{
+		Location: jsonLocation{
 			File:   p.Position.Filename,
 			Line:   p.Position.Line,
 			Column: p.Position.Column,
 		},
-		Message: p.Text,
+		Message:        p.Text,
+		Configurations: p.Configurations,
+		Variants:       p.Variants,
+		Owners:         p.Owners,
+	}
+	for _, r := range p.Related {
+		jp.Related = append(jp.Related, jsonRelated{
+			Location: jsonLocation{
+				File:   r.Position.Filename,
+				Line:   r.Position.Line,
+				Column: r.Position.Column,
+			},
+			Message: r.Message,
+		})
 	}
 	_ = json.NewEncoder(o.W).Encode(jp)
 }
 
+// ReadJSON reads problems in the format written by JSON.
+func ReadJSON(r io.Reader) ([]lint.Problem, error) {
+	var ps []lint.Problem
+	dec := json.NewDecoder(r)
+	for {
+		var jp jsonProblem
+		if err := dec.Decode(&jp); err == io.EOF {
+			return ps, nil
+		} else if err != nil {
+			return nil, err
+		}
+		p := lint.Problem{
+			Position: token.Position{
+				Filename: jp.Location.File,
+				Line:     jp.Location.Line,
+				Column:   jp.Location.Column,
+			},
+			Text:           jp.Message,
+			Check:          jp.Code,
+			Configurations: jp.Configurations,
+			Variants:       jp.Variants,
+			Owners:         jp.Owners,
+		}
+		for _, r := range jp.Related {
+			p.Related = append(p.Related, lint.RelatedInformation{
+				Position: token.Position{
+					Filename: r.Location.File,
+					Line:     r.Location.Line,
+					Column:   r.Location.Column,
+				},
+				Message: r.Message,
+			})
+		}
+		switch jp.Severity {
+		case "warning":
+			p.Severity = lint.Warning
+		case "ignored":
+			p.Severity = lint.Ignored
+		default:
+			p.Severity = lint.Error
+		}
+		ps = append(ps, p)
+	}
+}
+
 type Stylish struct {
 	W io.Writer
 
 	prevFile string
 	tw       *tabwriter.Writer
+	// owners counts problems per owner; unowned problems are
+	// counted under the empty string.
+	owners map[string]int
 }
 
 func (o *Stylish) Format(p lint.Problem) {
@@ -111,11 +212,25 @@ func (o *Stylish) Format(p lint.Problem) {
 			o.tw.Flush()
 			fmt.Fprintln(o.W)
 		}
-		fmt.Fprintln(o.W, p.Position.Filename)
+		if len(p.Owners) > 0 {
+			fmt.Fprintf(o.W, "%s (%s)\n", p.Position.Filename, strings.Join(p.Owners, " "))
+		} else {
+			fmt.Fprintln(o.W, p.Position.Filename)
+		}
 		o.prevFile = p.Position.Filename
 		o.tw = tabwriter.NewWriter(o.W, 0, 4, 2, ' ', 0)
 	}
-	fmt.Fprintf(o.tw, "  (%d, %d)\t%s\t%s\n", p.Position.Line, p.Position.Column, p.Check, p.Text)
+	fmt.Fprintf(o.tw, "  (%d, %d)\t%s\t%s%s\n", p.Position.Line, p.Position.Column, p.Check, p.Text, configurations(p))
+
+	if o.owners == nil {
+		o.owners = map[string]int{}
+	}
+	if len(p.Owners) == 0 {
+		o.owners[""]++
+	}
+	for _, owner := range p.Owners {
+		o.owners[owner]++
+	}
 }
 
 func (o *Stylish) Stats(total, errors, warnings int) {
@@ -125,4 +240,29 @@ func (o *Stylish) Stats(total, errors, warnings int) {
 	}
 	fmt.Fprintf(o.W, " ✖ %d problems (%d errors, %d warnings)\n",
 		total, errors, warnings)
+
+	if len(o.owners) == 0 || (len(o.owners) == 1 && o.owners[""] > 0) {
+		// Problems haven't been annotated with owners.
+		return
+	}
+	var owners []string
+	for owner := range o.owners {
+		owners = append(owners, owner)
+	}
+	sort.Slice(owners, func(i, j int) bool {
+		oi, oj := owners[i], owners[j]
+		if o.owners[oi] != o.owners[oj] {
+			return o.owners[oi] > o.owners[oj]
+		}
+		return oi < oj
+	})
+	tw := tabwriter.NewWriter(o.W, 0, 4, 2, ' ', 0)
+	for _, owner := range owners {
+		name := owner
+		if name == "" {
+			name = "(unowned)"
+		}
+		fmt.Fprintf(tw, "   %s\t%d\n", name, o.owners[owner])
+	}
+	tw.Flush()
 }
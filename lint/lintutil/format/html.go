@@ -0,0 +1,328 @@
+package format
+
+import (
+	"html/template"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"honnef.co/go/tools/lint"
+)
+
+// snippetContext is the number of lines shown before and after the
+// offending lines.
+const snippetContext = 2
+
+// HTML writes a self-contained HTML report. Because problems are
+// grouped by package and check, the report is only written by Stats,
+// once all problems have been formatted.
+type HTML struct {
+	W io.Writer
+	// Docs maps check IDs to their documentation.
+	Docs map[string]string
+
+	problems []lint.Problem
+	files    sourceFiles
+}
+
+func (o *HTML) Format(p lint.Problem) {
+	o.problems = append(o.problems, p)
+}
+
+type htmlLine struct {
+	Number                   int
+	Before, Highlight, After string
+}
+
+type htmlProblem struct {
+	Position       string
+	Severity       string
+	Text           string
+	Configurations []string
+	Owners         []string
+	Snippet        []htmlLine
+}
+
+type htmlCheck struct {
+	ID       string
+	Title    string
+	Doc      string
+	Problems []htmlProblem
+}
+
+type htmlPackage struct {
+	Path   string
+	Checks []*htmlCheck
+}
+
+type htmlCount struct {
+	ID      string
+	Count   int
+	Percent int
+}
+
+type htmlReport struct {
+	Total, Errors, Warnings int
+	Packages                []*htmlPackage
+	Counts                  []htmlCount
+	Severities              []string
+}
+
+// sourceFiles caches the lines of source files, for showing the
+// code that problems refer to.
+type sourceFiles map[string][]string
+
+// lines returns the lines of filename, or nil if it can't be read.
+func (s *sourceFiles) lines(filename string) []string {
+	if *s == nil {
+		*s = sourceFiles{}
+	}
+	lines, ok := (*s)[filename]
+	if !ok {
+		b, err := ioutil.ReadFile(filename)
+		if err == nil {
+			lines = strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+		}
+		(*s)[filename] = lines
+	}
+	return lines
+}
+
+// snippet returns the lines surrounding a problem, with the offending
+// range split out for highlighting.
+func (o *HTML) snippet(p lint.Problem) []htmlLine {
+	if !p.Position.IsValid() {
+		return nil
+	}
+	lines := o.files.lines(p.Position.Filename)
+	start, end := p.Position, p.End
+	if !end.IsValid() || end.Line < start.Line || end.Filename != start.Filename {
+		end = start
+		end.Column = 0
+	}
+	if start.Line > len(lines) {
+		return nil
+	}
+	clamp := func(col int, line string) int {
+		switch {
+		case col < 0:
+			return 0
+		case col > len(line):
+			return len(line)
+		}
+		return col
+	}
+
+	var out []htmlLine
+	first := start.Line - snippetContext
+	if first < 1 {
+		first = 1
+	}
+	last := end.Line + snippetContext
+	if last > len(lines) {
+		last = len(lines)
+	}
+	for n := first; n <= last; n++ {
+		line := strings.TrimRight(lines[n-1], "\r")
+		l := htmlLine{Number: n}
+		switch {
+		case n < start.Line || n > end.Line:
+			l.Before = line
+		default:
+			from, to := 0, len(line)
+			if n == start.Line {
+				from = clamp(start.Column-1, line)
+			}
+			if n == end.Line && end.Column > 0 {
+				to = clamp(end.Column-1, line)
+			}
+			if to < from {
+				to = from
+			}
+			l.Before, l.Highlight, l.After = line[:from], line[from:to], line[to:]
+		}
+		out = append(out, l)
+	}
+	return out
+}
+
+// docTitle returns the first line of a check's documentation.
+func docTitle(doc string) string {
+	if i := strings.Index(doc, "\n"); i != -1 {
+		return doc[:i]
+	}
+	return doc
+}
+
+func (o *HTML) Stats(total, errors, warnings int) {
+	_ = htmlTemplate.Execute(o.W, o.report(total, errors, warnings))
+}
+
+// report groups the problems by package and check and counts them by
+// check.
+func (o *HTML) report(total, errors, warnings int) htmlReport {
+	report := htmlReport{Total: total, Errors: errors, Warnings: warnings}
+	pkgs := map[string]*htmlPackage{}
+	checks := map[[2]string]*htmlCheck{}
+	counts := map[string]int{}
+	severities := map[string]bool{}
+	for _, p := range o.problems {
+		path := "-"
+		if p.Package != nil {
+			path = p.Package.PkgPath
+		} else if p.Position.Filename != "" {
+			path = filepath.Dir(p.Position.Filename)
+		}
+		pkg, ok := pkgs[path]
+		if !ok {
+			pkg = &htmlPackage{Path: path}
+			pkgs[path] = pkg
+			report.Packages = append(report.Packages, pkg)
+		}
+		check, ok := checks[[2]string{path, p.Check}]
+		if !ok {
+			doc := o.Docs[p.Check]
+			check = &htmlCheck{ID: p.Check, Title: docTitle(doc), Doc: doc}
+			checks[[2]string{path, p.Check}] = check
+			pkg.Checks = append(pkg.Checks, check)
+		}
+		sev := severity(p.Severity)
+		severities[sev] = true
+		check.Problems = append(check.Problems, htmlProblem{
+			Position:       relativePositionString(p.Position),
+			Severity:       sev,
+			Text:           p.Text,
+			Configurations: p.Configurations,
+			Owners:         p.Owners,
+			Snippet:        o.snippet(p),
+		})
+		counts[p.Check]++
+	}
+
+	sort.Slice(report.Packages, func(i, j int) bool {
+		return report.Packages[i].Path < report.Packages[j].Path
+	})
+	for _, pkg := range report.Packages {
+		sort.Slice(pkg.Checks, func(i, j int) bool {
+			return pkg.Checks[i].ID < pkg.Checks[j].ID
+		})
+	}
+	max := 0
+	for id, n := range counts {
+		report.Counts = append(report.Counts, htmlCount{ID: id, Count: n})
+		if n > max {
+			max = n
+		}
+	}
+	sort.Slice(report.Counts, func(i, j int) bool {
+		ci, cj := report.Counts[i], report.Counts[j]
+		if ci.Count != cj.Count {
+			return ci.Count > cj.Count
+		}
+		return ci.ID < cj.ID
+	})
+	for i := range report.Counts {
+		report.Counts[i].Percent = report.Counts[i].Count * 100 / max
+	}
+	for _, sev := range []string{"error", "warning", "ignored"} {
+		if severities[sev] {
+			report.Severities = append(report.Severities, sev)
+		}
+	}
+	return report
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Staticcheck report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+h2 { border-bottom: 1px solid #ccc; font-family: monospace; }
+h3 { font-size: 1em; margin-bottom: 0.3em; }
+pre { background: #f6f6f6; padding: 0.5em; overflow-x: auto; margin: 0.3em 0 1em; }
+.doc pre { background: #fffbe6; white-space: pre-wrap; }
+.lineno { color: #999; user-select: none; }
+mark { background: #fcc; }
+.problem { margin-left: 1em; }
+.severity { font-size: 0.8em; padding: 0 0.4em; border-radius: 3px; color: white; }
+.severity-error { background: #c00; }
+.severity-warning { background: #d80; }
+.severity-ignored { background: #888; }
+.meta { color: #666; font-size: 0.9em; }
+table.chart { border-collapse: collapse; }
+table.chart td { padding: 1px 0.5em; font-family: monospace; }
+.bar { background: #48c; height: 1em; }
+#filters label { margin-right: 1em; }
+#filters select { min-width: 10em; }
+</style>
+</head>
+<body>
+<h1>Staticcheck report</h1>
+<p>{{.Total}} problems ({{.Errors}} errors, {{.Warnings}} warnings)</p>
+
+<table class="chart">
+{{range .Counts}}<tr><td>{{.ID}}</td><td>{{.Count}}</td><td style="width: 30em"><div class="bar" style="width: {{.Percent}}%"></div></td></tr>
+{{end}}</table>
+
+<form id="filters">
+<p>
+{{range .Severities}}<label><input type="checkbox" name="severity" value="{{.}}" checked> {{.}}</label>
+{{end}}</p>
+<p><label>Checks<br><select name="check" multiple size="6">
+{{range .Counts}}<option value="{{.ID}}" selected>{{.ID}} ({{.Count}})</option>
+{{end}}</select></label></p>
+</form>
+
+{{range .Packages}}<div class="package">
+<h2>{{.Path}}</h2>
+{{range .Checks}}<div class="check" data-check="{{.ID}}">
+<h3>{{.ID}}{{if .Title}}: {{.Title}}{{end}}</h3>
+{{if .Doc}}<details class="doc"><summary>Documentation</summary><pre>{{.Doc}}</pre></details>{{end}}
+{{range .Problems}}<div class="problem" data-severity="{{.Severity}}">
+<p><span class="severity severity-{{.Severity}}">{{.Severity}}</span> <code>{{.Position}}</code>: {{.Text}}
+{{if .Configurations}}<span class="meta">[{{range $i, $c := .Configurations}}{{if $i}} {{end}}{{$c}}{{end}}]</span>{{end}}
+{{if .Owners}}<span class="meta">owned by {{range $i, $o := .Owners}}{{if $i}}, {{end}}{{$o}}{{end}}</span>{{end}}</p>
+{{if .Snippet}}<pre>{{range .Snippet}}<span class="lineno">{{printf "%5d" .Number}}</span>  {{.Before}}{{if .Highlight}}<mark>{{.Highlight}}</mark>{{end}}{{.After}}
+{{end}}</pre>{{end}}
+</div>
+{{end}}</div>
+{{end}}</div>
+{{end}}
+
+<script>
+(function() {
+	var form = document.getElementById("filters");
+	function update() {
+		var severities = {}, checks = {};
+		form.querySelectorAll("input[name=severity]").forEach(function(el) {
+			severities[el.value] = el.checked;
+		});
+		form.querySelectorAll("select[name=check] option").forEach(function(el) {
+			checks[el.value] = el.selected;
+		});
+		document.querySelectorAll(".check").forEach(function(check) {
+			var visible = 0;
+			check.querySelectorAll(".problem").forEach(function(p) {
+				var show = checks[check.dataset.check] && severities[p.dataset.severity];
+				p.style.display = show ? "" : "none";
+				if (show) visible++;
+			});
+			check.style.display = visible ? "" : "none";
+		});
+		document.querySelectorAll(".package").forEach(function(pkg) {
+			var visible = Array.prototype.some.call(pkg.querySelectorAll(".check"), function(c) {
+				return c.style.display !== "none";
+			});
+			pkg.style.display = visible ? "" : "none";
+		});
+	}
+	form.addEventListener("change", update);
+})();
+</script>
+</body>
+</html>
+`))
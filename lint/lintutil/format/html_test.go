@@ -0,0 +1,118 @@
+package format
+
+import (
+	"fmt"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+	"honnef.co/go/tools/lint"
+)
+
+// formatSnippet formats lines as number:before[highlight]after,
+// separated by |.
+func formatSnippet(lines []htmlLine) string {
+	var out []string
+	for _, l := range lines {
+		if l.Highlight == "" && l.After == "" {
+			out = append(out, fmt.Sprintf("%d:%s", l.Number, l.Before))
+		} else {
+			out = append(out, fmt.Sprintf("%d:%s[%s]%s", l.Number, l.Before, l.Highlight, l.After))
+		}
+	}
+	return strings.Join(out, "|")
+}
+
+func TestHTMLSnippet(t *testing.T) {
+	o := &HTML{files: sourceFiles{"a.go": {
+		"package a",
+		"",
+		"func fn(x bool) {",
+		"\tif x {",
+		"\t\treturn",
+		"\t}",
+		"}",
+	}}}
+	pos := func(line, col int) token.Position {
+		return token.Position{Filename: "a.go", Line: line, Column: col}
+	}
+	tests := []struct {
+		name       string
+		start, end token.Position
+		want       string
+	}{
+		{"single line", pos(4, 5), pos(4, 6),
+			"2:|3:func fn(x bool) {|4:\tif [x] {|5:\t\treturn|6:\t}"},
+		{"tab-indented", pos(5, 3), pos(5, 9),
+			"3:func fn(x bool) {|4:\tif x {|5:\t\t[return]|6:\t}|7:}"},
+		{"multi-line", pos(4, 2), pos(6, 3),
+			"2:|3:func fn(x bool) {|4:\t[if x {]|5:[\t\treturn]|6:[\t}]|7:}"},
+		{"no end", pos(3, 1), token.Position{},
+			"1:package a|2:|3:[func fn(x bool) {]|4:\tif x {|5:\t\treturn"},
+		{"end before start", pos(3, 6), pos(2, 1),
+			"1:package a|2:|3:func [fn(x bool) {]|4:\tif x {|5:\t\treturn"},
+		{"end in other file", pos(3, 6), token.Position{Filename: "b.go", Line: 3, Column: 8},
+			"1:package a|2:|3:func [fn(x bool) {]|4:\tif x {|5:\t\treturn"},
+		{"columns past the end of the line", pos(1, 50), pos(1, 60),
+			"1:package a|2:|3:func fn(x bool) {"},
+		{"end past the last line", pos(7, 1), pos(9, 1),
+			"5:\t\treturn|6:\t}|7:[}]"},
+		{"line out of range", pos(8, 1), token.Position{}, ""},
+		{"invalid position", token.Position{}, token.Position{}, ""},
+	}
+	for _, tt := range tests {
+		got := formatSnippet(o.snippet(lint.Problem{Position: tt.start, End: tt.end}))
+		if got != tt.want {
+			t.Errorf("%s: got\n%q\nwant\n%q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHTMLReport(t *testing.T) {
+	pkg := func(path string) *lint.Pkg {
+		return &lint.Pkg{Package: &packages.Package{PkgPath: path}}
+	}
+	o := &HTML{Docs: map[string]string{"SA4006": "Unused value\n\nMore."}, files: sourceFiles{}}
+	for _, p := range []lint.Problem{
+		{Check: "SA4006", Package: pkg("b"), Text: "b1"},
+		{Check: "S1002", Package: pkg("a"), Text: "a1"},
+		{Check: "SA4006", Package: pkg("a"), Text: "a2", Severity: lint.Warning},
+		{Check: "S1002", Package: pkg("a"), Text: "a3"},
+		{Check: "compile", Position: token.Position{Filename: "/c/c.go"}, Text: "c1"},
+		{Check: "SA4006", Text: "d1"},
+	} {
+		o.Format(p)
+	}
+	report := o.report(6, 5, 1)
+
+	var got []string
+	for _, pkg := range report.Packages {
+		for _, check := range pkg.Checks {
+			var texts []string
+			for _, p := range check.Problems {
+				texts = append(texts, p.Text)
+			}
+			got = append(got, fmt.Sprintf("%s %s %q %s", pkg.Path, check.ID, check.Title, strings.Join(texts, ",")))
+		}
+	}
+	want := []string{
+		`- SA4006 "Unused value" d1`,
+		`/c compile "" c1`,
+		`a S1002 "" a1,a3`,
+		`a SA4006 "Unused value" a2`,
+		`b SA4006 "Unused value" b1`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got groups\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	wantCounts := []htmlCount{{"SA4006", 3, 100}, {"S1002", 2, 66}, {"compile", 1, 33}}
+	if !reflect.DeepEqual(report.Counts, wantCounts) {
+		t.Errorf("got counts %v, want %v", report.Counts, wantCounts)
+	}
+	if want := []string{"error", "warning"}; !reflect.DeepEqual(report.Severities, want) {
+		t.Errorf("got severities %v, want %v", report.Severities, want)
+	}
+}
@@ -0,0 +1,116 @@
+package format
+
+import (
+	"fmt"
+	"go/token"
+	"io"
+	"strings"
+
+	"honnef.co/go/tools/lint"
+)
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiYellow = "\x1b[33m"
+	ansiBlue   = "\x1b[34m"
+	ansiCyan   = "\x1b[36m"
+	ansiFaint  = "\x1b[2m"
+)
+
+// Pretty prints problems together with the offending source line,
+// underlining the problem's range, and the first line of the check's
+// documentation.
+type Pretty struct {
+	W io.Writer
+	// Docs maps check IDs to their documentation.
+	Docs map[string]string
+	// Color enables ANSI colors.
+	Color bool
+
+	files sourceFiles
+}
+
+func (o *Pretty) color(code, s string) string {
+	if !o.Color || s == "" {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+func (o *Pretty) Format(p lint.Problem) {
+	sev := severity(p.Severity)
+	sevColor := ansiRed
+	if p.Severity != lint.Error {
+		sevColor = ansiYellow
+	}
+	fmt.Fprintf(o.W, "%s: %s: %s%s\n",
+		o.color(ansiBold, relativePositionString(p.Position)),
+		o.color(sevColor, sev),
+		o.color(ansiBold, p.String()),
+		configurations(p))
+	o.excerpt(p.Position, p.End)
+	for _, r := range p.Related {
+		fmt.Fprintf(o.W, "  %s %s: %s\n", o.color(ansiBlue, "note:"), relativePositionString(r.Position), r.Message)
+		o.excerpt(r.Position, token.Position{})
+	}
+	if title := docTitle(o.Docs[p.Check]); title != "" {
+		fmt.Fprintf(o.W, "  %s\n", o.color(ansiFaint, "= "+p.Check+": "+title))
+	}
+	if len(p.Owners) > 0 {
+		fmt.Fprintf(o.W, "  %s\n", o.color(ansiFaint, "owned by "+strings.Join(p.Owners, ", ")))
+	}
+	fmt.Fprintln(o.W)
+}
+
+// excerpt prints the line of start, underlining the range up to end.
+// Ranges spanning multiple lines are underlined up to the end of the
+// first line.
+func (o *Pretty) excerpt(start, end token.Position) {
+	if !start.IsValid() {
+		return
+	}
+	lines := o.files.lines(start.Filename)
+	if start.Line > len(lines) {
+		return
+	}
+	line := strings.TrimRight(lines[start.Line-1], "\r")
+	from := start.Column - 1
+	if from < 0 || from > len(line) {
+		from = 0
+	}
+	to := from + 1
+	switch {
+	case end.IsValid() && end.Filename == start.Filename && end.Line == start.Line && end.Column-1 > from:
+		to = end.Column - 1
+	case end.IsValid() && end.Filename == start.Filename && end.Line > start.Line:
+		to = len(line)
+	}
+	if to > len(line) {
+		to = len(line)
+	}
+
+	// Reuse the line's tabs in the indentation of the underline, so
+	// that it lines up regardless of the terminal's tab width.
+	var pad strings.Builder
+	for _, r := range line[:from] {
+		if r == '\t' {
+			pad.WriteByte('\t')
+		} else {
+			pad.WriteByte(' ')
+		}
+	}
+	n := len([]rune(line[from:to]))
+	if n == 0 {
+		n = 1
+	}
+	number := fmt.Sprintf("%5d", start.Line)
+	fmt.Fprintf(o.W, "%s %s %s\n", o.color(ansiBlue, number), o.color(ansiBlue, "|"), line)
+	fmt.Fprintf(o.W, "%s %s %s%s\n", strings.Repeat(" ", len(number)), o.color(ansiBlue, "|"), pad.String(), o.color(ansiCyan, strings.Repeat("^", n)))
+}
+
+func (o *Pretty) Stats(total, errors, warnings int) {
+	fmt.Fprintf(o.W, " ✖ %d problems (%d errors, %d warnings)\n",
+		total, errors, warnings)
+}
@@ -0,0 +1,55 @@
+package format
+
+import (
+	"bytes"
+	"go/token"
+	"testing"
+)
+
+func TestPrettyExcerpt(t *testing.T) {
+	files := sourceFiles{"a.go": {
+		"package a",
+		"\tif x == true {",
+		"var s = \"é\" + y\r",
+		"}",
+	}}
+	pos := func(line, col int) token.Position {
+		return token.Position{Filename: "a.go", Line: line, Column: col}
+	}
+	tests := []struct {
+		name       string
+		start, end token.Position
+		want       string
+	}{
+		{"single line", pos(1, 9), pos(1, 10),
+			"    1 | package a\n      |         ^\n"},
+		{"range", pos(1, 1), pos(1, 8),
+			"    1 | package a\n      | ^^^^^^^\n"},
+		{"tab-indented", pos(2, 5), pos(2, 14),
+			"    2 | \tif x == true {\n      | \t   ^^^^^^^^^\n"},
+		{"multi-line", pos(2, 2), pos(4, 2),
+			"    2 | \tif x == true {\n      | \t^^^^^^^^^^^^^^\n"},
+		{"no end", pos(2, 5), token.Position{},
+			"    2 | \tif x == true {\n      | \t   ^\n"},
+		{"end in other file", pos(2, 5), token.Position{Filename: "b.go", Line: 2, Column: 14},
+			"    2 | \tif x == true {\n      | \t   ^\n"},
+		// the underline counts runes, and the carriage return is
+		// dropped
+		{"multi-byte", pos(3, 10), pos(3, 13),
+			"    3 | var s = \"é\" + y\n      |          ^^\n"},
+		{"end past the end of the line", pos(4, 1), pos(4, 10),
+			"    4 | }\n      | ^\n"},
+		{"start past the end of the line", pos(1, 50), token.Position{},
+			"    1 | package a\n      | ^\n"},
+		{"line out of range", pos(5, 1), token.Position{}, ""},
+		{"invalid position", token.Position{}, token.Position{}, ""},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		o := &Pretty{W: &buf, files: files}
+		o.excerpt(tt.start, tt.end)
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%s: got\n%q\nwant\n%q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package format
+
+import (
+	"encoding/json"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"honnef.co/go/tools/lint"
+	"honnef.co/go/tools/version"
+)
+
+// SARIF writes problems in the Static Analysis Results Interchange
+// Format 2.1.0, as understood by code scanning services. Like HTML,
+// it only writes its output in Stats.
+type SARIF struct {
+	W io.Writer
+	// Docs maps check IDs to their documentation.
+	Docs map[string]string
+	// Root is the directory that the %SRCROOT% base URI refers to,
+	// usually the root of the module. Files below it are reported
+	// relative to it, all others by absolute URIs.
+	Root string
+
+	problems []lint.Problem
+	// lines caches the lines of files, for converting columns.
+	lines map[string][]string
+}
+
+func (o *SARIF) Format(p lint.Problem) {
+	o.problems = append(o.problems, p)
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	ColumnKind         string                           `json:"columnKind"`
+	Results            []sarifResult                    `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string        `json:"id"`
+	ShortDescription *sarifMessage `json:"shortDescription,omitempty"`
+	FullDescription  *sarifMessage `json:"fullDescription,omitempty"`
+	HelpURI          string        `json:"helpUri,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID           string             `json:"ruleId,omitempty"`
+	Level            string             `json:"level"`
+	Message          sarifMessage       `json:"message"`
+	Locations        []sarifLocation    `json:"locations,omitempty"`
+	RelatedLocations []sarifLocation    `json:"relatedLocations,omitempty"`
+	Suppressions     []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifLocation struct {
+	ID               *int                  `json:"id,omitempty"`
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind string `json:"kind"`
+}
+
+func sarifLevel(s lint.Severity) string {
+	switch s {
+	case lint.Error:
+		return "error"
+	case lint.Warning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// fileURI returns the file URI of the absolute path.
+func fileURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		// Windows paths, such as C:/foo, become file:///C:/foo.
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+// physical returns the location of start and end. Files below the
+// root are relative to the source root, which is how code scanning
+// services match them to their repositories.
+func (o *SARIF) physical(start, end token.Position) sarifPhysicalLocation {
+	loc := sarifPhysicalLocation{}
+	path := start.Filename
+	rel, err := filepath.Rel(o.Root, path)
+	if o.Root != "" && filepath.IsAbs(path) && err == nil &&
+		rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		loc.ArtifactLocation.URI = (&url.URL{Path: filepath.ToSlash(rel)}).String()
+		loc.ArtifactLocation.URIBaseID = "%SRCROOT%"
+	} else if filepath.IsAbs(path) {
+		loc.ArtifactLocation.URI = fileURI(path)
+	} else {
+		loc.ArtifactLocation.URI = (&url.URL{Path: filepath.ToSlash(path)}).String()
+		loc.ArtifactLocation.URIBaseID = "%SRCROOT%"
+	}
+	if start.Line > 0 {
+		loc.Region = &sarifRegion{StartLine: start.Line, StartColumn: o.column(start)}
+		if end.IsValid() && end.Filename == start.Filename {
+			loc.Region.EndLine = end.Line
+			loc.Region.EndColumn = o.column(end)
+		}
+	}
+	return loc
+}
+
+// column converts the column of pos, which counts bytes, to UTF-16
+// code units, which SARIF uses by default. If the file can't be
+// read, the column is returned unchanged.
+func (o *SARIF) column(pos token.Position) int {
+	if pos.Column <= 1 {
+		return pos.Column
+	}
+	if o.lines == nil {
+		o.lines = map[string][]string{}
+	}
+	lines, ok := o.lines[pos.Filename]
+	if !ok {
+		if b, err := ioutil.ReadFile(pos.Filename); err == nil {
+			lines = strings.Split(string(b), "\n")
+		}
+		o.lines[pos.Filename] = lines
+	}
+	if pos.Line < 1 || pos.Line > len(lines) {
+		return pos.Column
+	}
+	line := lines[pos.Line-1]
+	n := pos.Column - 1
+	if n > len(line) {
+		n = len(line)
+	}
+	col := 1
+	for _, r := range line[:n] {
+		if r >= 0x10000 {
+			// Encoded as a surrogate pair in UTF-16.
+			col += 2
+		} else {
+			col++
+		}
+	}
+	return col + (pos.Column - 1 - n)
+}
+
+func (o *SARIF) Stats(total, errors, warnings int) {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "staticcheck",
+			Version:        version.Version,
+			InformationURI: "https://staticcheck.io",
+			Rules:          []sarifRule{},
+		}},
+		ColumnKind: "utf16CodeUnits",
+		Results:    []sarifResult{},
+	}
+	if o.Root != "" {
+		uri := fileURI(o.Root)
+		if !strings.HasSuffix(uri, "/") {
+			uri += "/"
+		}
+		run.OriginalURIBaseIDs = map[string]sarifArtifactLocation{"%SRCROOT%": {URI: uri}}
+	}
+
+	seen := map[string]bool{}
+	for _, p := range o.problems {
+		if p.Check != "" && !seen[p.Check] {
+			seen[p.Check] = true
+			rule := sarifRule{ID: p.Check, HelpURI: "https://staticcheck.io/docs/checks#" + p.Check}
+			if doc := o.Docs[p.Check]; doc != "" {
+				rule.ShortDescription = &sarifMessage{Text: docTitle(doc)}
+				rule.FullDescription = &sarifMessage{Text: doc}
+			}
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+		}
+
+		res := sarifResult{
+			RuleID:  p.Check,
+			Level:   sarifLevel(p.Severity),
+			Message: sarifMessage{Text: p.Text},
+		}
+		if p.Position.Filename != "" {
+			res.Locations = []sarifLocation{{PhysicalLocation: o.physical(p.Position, p.End)}}
+		}
+		for i, r := range p.Related {
+			id := i + 1
+			res.RelatedLocations = append(res.RelatedLocations, sarifLocation{
+				ID:               &id,
+				PhysicalLocation: o.physical(r.Position, token.Position{}),
+				Message:          &sarifMessage{Text: r.Message},
+			})
+		}
+		if p.Severity == lint.Ignored {
+			res.Suppressions = []sarifSuppression{{Kind: "inSource"}}
+		}
+		run.Results = append(run.Results, res)
+	}
+	sort.Slice(run.Tool.Driver.Rules, func(i, j int) bool {
+		return run.Tool.Driver.Rules[i].ID < run.Tool.Driver.Rules[j].ID
+	})
+
+	enc := json.NewEncoder(o.W)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
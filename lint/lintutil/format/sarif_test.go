@@ -0,0 +1,51 @@
+package format
+
+import (
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSARIFPhysical(t *testing.T) {
+	dir, err := ioutil.TempDir("", "staticcheck-sarif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	root := filepath.Join(dir, "my module")
+	name := filepath.Join(root, "a", "a.go")
+	if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+		t.Fatal(err)
+	}
+	src := "package a\n\nvar s = \"é😀\" + x\n"
+	if err := ioutil.WriteFile(name, []byte(src), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	o := &SARIF{Root: root}
+	tests := []struct {
+		filename     string
+		line, column int
+		uri, base    string
+		wantColumn   int
+	}{
+		{name, 3, 1, "a/a.go", "%SRCROOT%", 1},
+		// é is one UTF-16 code unit, 😀 two
+		{name, 3, 20, "a/a.go", "%SRCROOT%", 17},
+		{filepath.Join(dir, "other dir", "b.go"), 1, 5, "file://" + filepath.ToSlash(dir) + "/other%20dir/b.go", "", 5},
+		{"rel/c.go", 1, 1, "rel/c.go", "%SRCROOT%", 1},
+	}
+	for _, tt := range tests {
+		pos := token.Position{Filename: tt.filename, Line: tt.line, Column: tt.column}
+		loc := o.physical(pos, token.Position{})
+		if loc.ArtifactLocation.URI != tt.uri || loc.ArtifactLocation.URIBaseID != tt.base {
+			t.Errorf("%s: got URI %q relative to %q, want %q relative to %q",
+				tt.filename, loc.ArtifactLocation.URI, loc.ArtifactLocation.URIBaseID, tt.uri, tt.base)
+		}
+		if loc.Region == nil || loc.Region.StartColumn != tt.wantColumn {
+			t.Errorf("%s:%d:%d: got region %+v, want column %d", tt.filename, tt.line, tt.column, loc.Region, tt.wantColumn)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package lintutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"honnef.co/go/tools/lint"
+)
+
+// A BuildConfig describes a combination of target platform and build
+// tags. Empty fields default to the host's values.
+type BuildConfig struct {
+	GOOS   string
+	GOARCH string
+	Tags   []string
+}
+
+func (bc BuildConfig) String() string {
+	s := bc.GOOS
+	if bc.GOARCH != "" {
+		s += "/" + bc.GOARCH
+	}
+	if len(bc.Tags) > 0 {
+		s += ":" + strings.Join(bc.Tags, ",")
+	}
+	return s
+}
+
+// buildEnv returns the environment for the go command, or nil if
+// the host's environment should be used unmodified.
+func buildEnv(extra []string, bc BuildConfig) []string {
+	if len(extra) == 0 && bc.GOOS == "" && bc.GOARCH == "" {
+		return nil
+	}
+	env := append(os.Environ(), extra...)
+	if bc.GOOS != "" {
+		env = append(env, "GOOS="+bc.GOOS)
+	}
+	if bc.GOARCH != "" {
+		env = append(env, "GOARCH="+bc.GOARCH)
+	}
+	return env
+}
+
+func parseBuildConfig(s string) (BuildConfig, error) {
+	var bc BuildConfig
+	platform := s
+	if i := strings.Index(s, ":"); i != -1 {
+		platform = s[:i]
+		for _, tag := range strings.Split(s[i+1:], ",") {
+			if tag != "" {
+				bc.Tags = append(bc.Tags, tag)
+			}
+		}
+	}
+	if platform != "" {
+		parts := strings.Split(platform, "/")
+		if len(parts) > 2 || parts[0] == "" {
+			return BuildConfig{}, fmt.Errorf("malformed build configuration %q", s)
+		}
+		bc.GOOS = parts[0]
+		if len(parts) == 2 {
+			bc.GOARCH = parts[1]
+		}
+	}
+	return bc, nil
+}
+
+type matrixFlag []BuildConfig
+
+func (m *matrixFlag) String() string {
+	var parts []string
+	for _, bc := range *m {
+		parts = append(parts, bc.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+func (m *matrixFlag) Set(s string) error {
+	*m = nil
+	for _, field := range strings.Fields(s) {
+		bc, err := parseBuildConfig(field)
+		if err != nil {
+			return err
+		}
+		*m = append(*m, bc)
+	}
+	if len(*m) == 0 && s != "" {
+		return errors.New("empty build matrix")
+	}
+	return nil
+}
+
+func (m *matrixFlag) Get() interface{} {
+	return []BuildConfig(*m)
+}
+
+type matrixKey struct {
+	pos   string
+	check string
+	text  string
+}
+
+// A matrixResult holds the results of linting in one build
+// configuration.
+type matrixResult struct {
+	config   string
+	problems []lint.Problem
+	// files contains the files that were part of the build.
+	files map[string]bool
+}
+
+// lintMatrix lints packages in each of the build configurations in
+// opt.Matrix and merges the results with mergeMatrix.
+func lintMatrix(cs []lint.Checker, paths []string, opt *Options) ([]lint.Problem, error) {
+	var results []matrixResult
+	for _, bc := range opt.Matrix {
+		name := bc.String()
+		ps, fs, err := lintBuildConfig(cs, paths, opt, bc)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+		results = append(results, matrixResult{config: name, problems: ps, files: fs})
+	}
+	return mergeMatrix(results), nil
+}
+
+// mergeMatrix merges the problems of several build configurations.
+// Problems at the same position, in the same check and with the same
+// text are reported once, annotated with the configurations they
+// occurred in.
+//
+// Unused identifiers (U1000) are only reported if they are unused in
+// every configuration whose build includes the identifier's file; an
+// identifier may well be used by code for a different platform.
+func mergeMatrix(results []matrixResult) []lint.Problem {
+	var (
+		out     []lint.Problem
+		indices = map[matrixKey]int{}
+		// files records, for each file, the configurations it was
+		// part of.
+		files = map[string][]string{}
+	)
+	for _, res := range results {
+		name := res.config
+		for f := range res.files {
+			files[f] = append(files[f], name)
+		}
+		for _, p := range res.problems {
+			k := matrixKey{p.Position.String(), p.Check, p.Text}
+			idx, ok := indices[k]
+			if !ok {
+				idx = len(out)
+				indices[k] = idx
+				out = append(out, p)
+			}
+			cfgs := out[idx].Configurations
+			if len(cfgs) == 0 || cfgs[len(cfgs)-1] != name {
+				out[idx].Configurations = append(cfgs, name)
+			}
+		variants:
+			for _, v := range p.Variants {
+				for _, w := range out[idx].Variants {
+					if v == w {
+						continue variants
+					}
+				}
+				out[idx].Variants = append(out[idx].Variants, v)
+			}
+		}
+	}
+
+	filtered := out[:0]
+	for _, p := range out {
+		if p.Check == "U1000" && len(p.Configurations) < len(files[p.Position.Filename]) {
+			// used in at least one configuration
+			continue
+		}
+		filtered = append(filtered, p)
+	}
+	sortProblems(filtered)
+	return filtered
+}
@@ -0,0 +1,119 @@
+package lintutil
+
+import (
+	"fmt"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+
+	"honnef.co/go/tools/lint"
+)
+
+func TestParseBuildConfig(t *testing.T) {
+	var tests = []struct {
+		in  string
+		out BuildConfig
+		err bool
+	}{
+		{"linux", BuildConfig{GOOS: "linux"}, false},
+		{"windows/amd64", BuildConfig{GOOS: "windows", GOARCH: "amd64"}, false},
+		{"linux/arm64:purego,netgo", BuildConfig{GOOS: "linux", GOARCH: "arm64", Tags: []string{"purego", "netgo"}}, false},
+		{":integration", BuildConfig{Tags: []string{"integration"}}, false},
+		{"linux/arm/v7", BuildConfig{}, true},
+		{"/amd64", BuildConfig{}, true},
+	}
+
+	for _, tt := range tests {
+		bc, err := parseBuildConfig(tt.in)
+		if (err != nil) != tt.err {
+			t.Errorf("parseBuildConfig(%q): unexpected error state %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(bc, tt.out) {
+			t.Errorf("parseBuildConfig(%q) = %#v, want %#v", tt.in, bc, tt.out)
+		}
+		if err == nil && bc.String() != tt.in {
+			t.Errorf("%#v.String() = %q, want %q", bc, bc.String(), tt.in)
+		}
+	}
+}
+
+func TestMergeMatrix(t *testing.T) {
+	problem := func(file string, line int, check, text string) lint.Problem {
+		return lint.Problem{Position: token.Position{Filename: file, Line: line}, Check: check, Text: text}
+	}
+	files := func(names ...string) map[string]bool {
+		m := map[string]bool{}
+		for _, name := range names {
+			m[name] = true
+		}
+		return m
+	}
+	tests := []struct {
+		name    string
+		results []matrixResult
+		want    []string
+	}{
+		{
+			"identical problems are merged",
+			[]matrixResult{
+				{"linux", []lint.Problem{problem("/a.go", 1, "SA4006", "x is unused")}, files("/a.go")},
+				{"windows", []lint.Problem{problem("/a.go", 1, "SA4006", "x is unused")}, files("/a.go")},
+			},
+			[]string{"/a.go:1 SA4006 x is unused [linux windows]"},
+		},
+		{
+			"different texts, checks or positions aren't merged",
+			[]matrixResult{
+				{"linux", []lint.Problem{
+					problem("/a.go", 1, "SA4006", "x is unused"),
+					problem("/a.go", 2, "SA4006", "x is unused"),
+				}, files("/a.go")},
+				{"windows", []lint.Problem{
+					problem("/a.go", 1, "SA4006", "y is unused"),
+					problem("/a.go", 1, "SA4017", "x is unused"),
+				}, files("/a.go")},
+			},
+			[]string{
+				"/a.go:1 SA4006 x is unused [linux]",
+				"/a.go:1 SA4017 x is unused [windows]",
+				"/a.go:1 SA4006 y is unused [windows]",
+				"/a.go:2 SA4006 x is unused [linux]",
+			},
+		},
+		{
+			"U1000 is dropped if the object is used in one configuration",
+			[]matrixResult{
+				{"linux", []lint.Problem{problem("/a.go", 1, "U1000", "func f is unused")}, files("/a.go")},
+				{"windows", nil, files("/a.go")},
+			},
+			nil,
+		},
+		{
+			"U1000 is kept if the file isn't part of the other configuration",
+			[]matrixResult{
+				{"linux", []lint.Problem{problem("/a_linux.go", 1, "U1000", "func f is unused")}, files("/a.go", "/a_linux.go")},
+				{"windows", nil, files("/a.go")},
+			},
+			[]string{"/a_linux.go:1 U1000 func f is unused [linux]"},
+		},
+		{
+			"U1000 is kept if the object is unused everywhere",
+			[]matrixResult{
+				{"linux", []lint.Problem{problem("/a.go", 1, "U1000", "func f is unused")}, files("/a.go")},
+				{"windows", []lint.Problem{problem("/a.go", 1, "U1000", "func f is unused")}, files("/a.go")},
+			},
+			[]string{"/a.go:1 U1000 func f is unused [linux windows]"},
+		},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, p := range mergeMatrix(tt.results) {
+			got = append(got, fmt.Sprintf("%s %s %s [%s]", p.Position, p.Check, p.Text, strings.Join(p.Configurations, " ")))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.name, strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+		}
+	}
+}
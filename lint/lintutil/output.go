@@ -0,0 +1,167 @@
+package lintutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"honnef.co/go/tools/lint/lintutil/format"
+)
+
+// An output is a destination for problems, as specified by the -o
+// flag.
+type output struct {
+	format string
+	// path is the file to write to, or "-" for standard output.
+	path string
+}
+
+func (o output) String() string {
+	return o.format + "=" + o.path
+}
+
+// outputsFlag collects repeated -o format=path flags.
+type outputsFlag []output
+
+func (o *outputsFlag) String() string {
+	var parts []string
+	for _, out := range *o {
+		parts = append(parts, out.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+func (o *outputsFlag) Set(s string) error {
+	out := output{format: s, path: "-"}
+	if i := strings.Index(s, "="); i != -1 {
+		out.format, out.path = s[:i], s[i+1:]
+	}
+	if out.format == "" || out.path == "" {
+		return errors.New("outputs must be of the form format=path")
+	}
+	*o = append(*o, out)
+	return nil
+}
+
+func (o *outputsFlag) Get() interface{} {
+	return []output(*o)
+}
+
+// newFormatter returns the formatter called name, writing to f.
+func newFormatter(name string, f *os.File, docs func() map[string]string) (format.Formatter, error) {
+	switch name {
+	case "text":
+		return format.Text{W: f}, nil
+	case "stylish":
+		return &format.Stylish{W: f}, nil
+	case "json":
+		return format.JSON{W: f}, nil
+	case "pretty":
+		return &format.Pretty{W: f, Docs: docs(), Color: isTerminal(f)}, nil
+	case "html":
+		return &format.HTML{W: f, Docs: docs()}, nil
+	case "sarif":
+		return &format.SARIF{W: f, Docs: docs(), Root: moduleRoot()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported output format %q", name)
+	}
+}
+
+// moduleRoot returns the root of the module containing the working
+// directory, or the working directory if it isn't in a module.
+func moduleRoot() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for dir := cwd; ; {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return cwd
+		}
+		dir = parent
+	}
+}
+
+// openOutputs returns a formatter writing to all of outputs, as well
+// as a function closing the files. Files are written to temporary
+// files next to their destinations, which the closing function renames
+// into place if commit is true and removes otherwise, so that a failed
+// run doesn't clobber earlier results. Unless one of the outputs is
+// standard output, problems are also written to it in the format
+// stdout.
+func openOutputs(stdout string, outputs []output, docs func() map[string]string) (format.Formatter, func(commit bool) error, error) {
+	type file struct {
+		*os.File
+		path string
+	}
+	var (
+		fs     format.Multi
+		files  []file
+		seen   = map[string]bool{}
+		closer = func(commit bool) error {
+			var first error
+			for _, f := range files {
+				err := f.Close()
+				if err == nil && commit {
+					err = os.Rename(f.Name(), f.path)
+				}
+				if err != nil || !commit {
+					os.Remove(f.Name())
+				}
+				if err != nil && first == nil {
+					first = err
+				}
+			}
+			files = nil
+			return first
+		}
+	)
+	if len(outputs) == 0 {
+		outputs = []output{{stdout, "-"}}
+	} else {
+		hasStdout := false
+		for _, out := range outputs {
+			hasStdout = hasStdout || out.path == "-"
+		}
+		if !hasStdout {
+			outputs = append([]output{{stdout, "-"}}, outputs...)
+		}
+	}
+
+	fail := func(err error) (format.Formatter, func(bool) error, error) {
+		closer(false)
+		return nil, nil, err
+	}
+	for _, out := range outputs {
+		if seen[out.path] {
+			return fail(fmt.Errorf("multiple outputs write to %s", out.path))
+		}
+		seen[out.path] = true
+
+		w := os.Stdout
+		if out.path != "-" {
+			var err error
+			tmp := fmt.Sprintf("%s.%d.tmp", out.path, os.Getpid())
+			w, err = os.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+			if err != nil {
+				return fail(err)
+			}
+			files = append(files, file{w, out.path})
+		}
+		f, err := newFormatter(out.format, w, docs)
+		if err != nil {
+			return fail(err)
+		}
+		fs = append(fs, f)
+	}
+	if len(fs) == 1 {
+		return fs[0], closer, nil
+	}
+	return fs, closer, nil
+}
@@ -0,0 +1,138 @@
+package lintutil
+
+import (
+	"encoding/json"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"honnef.co/go/tools/lint"
+)
+
+func TestOutputs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "staticcheck-outputs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var outputs outputsFlag
+	sarif := filepath.Join(dir, "out.sarif")
+	text := filepath.Join(dir, "out.txt")
+	for _, s := range []string{"sarif=" + sarif, "stylish=" + text, "json=-"} {
+		if err := outputs.Set(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := outputs.Set("=out.json"); err == nil {
+		t.Error("expected an error for an output without a format")
+	}
+
+	stdout, err := os.Create(filepath.Join(dir, "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(old *os.File) { os.Stdout = old }(os.Stdout)
+	os.Stdout = stdout
+
+	docs := func() map[string]string { return map[string]string{"SA4006": "Unused value\n\nMore."} }
+	f, closer, err := openOutputs("text", outputs, docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps := []lint.Problem{{
+		Position: token.Position{Filename: filepath.Join(dir, "a.go"), Line: 3, Column: 2},
+		Text:     "x is never used",
+		Check:    "SA4006",
+	}}
+	formatProblems(f, ps, []string{"all"})
+	if err := closer(true); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(sarif)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log struct {
+		Runs []struct {
+			Results []struct {
+				RuleID string
+				Level  string
+			}
+		}
+	}
+	if err := json.Unmarshal(b, &log); err != nil {
+		t.Fatalf("invalid SARIF: %s", err)
+	}
+	if len(log.Runs) != 1 || len(log.Runs[0].Results) != 1 ||
+		log.Runs[0].Results[0].RuleID != "SA4006" || log.Runs[0].Results[0].Level != "error" {
+		t.Errorf("unexpected SARIF output: %s", b)
+	}
+
+	b, err = ioutil.ReadFile(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "1 problems (1 errors, 0 warnings)") {
+		t.Errorf("stylish output is missing statistics: %s", b)
+	}
+
+	b, err = ioutil.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(b), `{"code":"SA4006"`) {
+		t.Errorf("standard output isn't JSON: %s", b)
+	}
+
+	if _, _, err := openOutputs("text", []output{{"text", "-"}, {"json", "-"}}, docs); err == nil {
+		t.Error("expected an error for multiple outputs to standard output")
+	}
+
+	// Discarding the outputs of a failed run must leave earlier
+	// results alone.
+	f, closer, err = openOutputs("text", []output{{"sarif", sarif}}, docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	formatProblems(f, nil, []string{"all"})
+	if err := closer(false); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := ioutil.ReadFile(sarif); err != nil || len(b) == 0 {
+		t.Errorf("discarded output clobbered %s: %v", sarif, err)
+	}
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fi := range fis {
+		if strings.HasSuffix(fi.Name(), ".tmp") {
+			t.Errorf("temporary file %s wasn't removed", fi.Name())
+		}
+	}
+}
+
+type recordFormatter struct{ ps []lint.Problem }
+
+func (f *recordFormatter) Format(p lint.Problem) { f.ps = append(f.ps, p) }
+
+func TestFormatProblemsSeverity(t *testing.T) {
+	ps := []lint.Problem{
+		{Check: "SA4006"},
+		{Check: "SA4006", Severity: lint.Warning},
+		{Check: "ST1000"},
+	}
+	f := &recordFormatter{}
+	formatProblems(f, ps, []string{"SA*"})
+	want := []lint.Severity{lint.Error, lint.Warning, lint.Warning}
+	for i, p := range f.ps {
+		if p.Severity != want[i] {
+			t.Errorf("problem %d: got severity %d, want %d", i, p.Severity, want[i])
+		}
+	}
+}
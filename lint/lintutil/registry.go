@@ -0,0 +1,115 @@
+package lintutil
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+
+	"honnef.co/go/tools/lint"
+)
+
+// A Registry is a set of checkers with unique names, prefixes and
+// check IDs.
+//
+// Custom linters can combine staticcheck's checkers with their own by
+// registering all of them and passing the registry's checkers to
+// ProcessFlagSet. Problems of registered checkers are subject to the
+// same linter directives, configuration files and output formats as
+// those of staticcheck's own checks. Checkers can read their own
+// options from the [checkers.<name>] section of configuration files,
+// using config.Config.Section.
+type Registry struct {
+	mu       sync.Mutex
+	checkers []lint.Checker
+	names    map[string]lint.Checker
+	prefixes map[string]lint.Checker
+	ids      map[string]lint.Checker
+}
+
+// DefaultRegistry is the registry used by Register and Checkers.
+var DefaultRegistry = &Registry{}
+
+// Register adds checkers to the registry. It returns an error, and
+// adds none of the checkers, if any of them has the same name or
+// prefix as another checker, if any of their checks have the same ID
+// as another check, or if a check's ID doesn't consist of the
+// checker's prefix followed by a number.
+func (r *Registry) Register(cs ...lint.Checker) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := map[string]lint.Checker{}
+	prefixes := map[string]lint.Checker{}
+	ids := map[string]lint.Checker{}
+	for k, v := range r.names {
+		names[k] = v
+	}
+	for k, v := range r.prefixes {
+		prefixes[k] = v
+	}
+	for k, v := range r.ids {
+		ids[k] = v
+	}
+
+	for _, c := range cs {
+		if other, ok := names[c.Name()]; ok {
+			return fmt.Errorf("checker name %q is already used by %T", c.Name(), other)
+		}
+		names[c.Name()] = c
+		if other, ok := prefixes[c.Prefix()]; ok {
+			return fmt.Errorf("checker prefix %q of %s is already used by %s", c.Prefix(), c.Name(), other.Name())
+		}
+		prefixes[c.Prefix()] = c
+		for _, check := range c.Checks() {
+			if !validCheckID(check.ID, c.Prefix()) {
+				return fmt.Errorf("check ID %q of %s must consist of the prefix %q followed by a number", check.ID, c.Name(), c.Prefix())
+			}
+			if other, ok := ids[check.ID]; ok {
+				return fmt.Errorf("check ID %q of %s is already used by %s", check.ID, c.Name(), other.Name())
+			}
+			ids[check.ID] = c
+		}
+	}
+
+	r.names = names
+	r.prefixes = prefixes
+	r.ids = ids
+	r.checkers = append(r.checkers, cs...)
+	return nil
+}
+
+// Checkers returns all registered checkers, in the order they were
+// registered in.
+func (r *Registry) Checkers() []lint.Checker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]lint.Checker(nil), r.checkers...)
+}
+
+func validCheckID(id, prefix string) bool {
+	if !strings.HasPrefix(id, prefix) || len(id) == len(prefix) {
+		return false
+	}
+	for _, r := range id[len(prefix):] {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	// FilterChecks determines categories by looking for the first
+	// digit.
+	return strings.IndexFunc(prefix, unicode.IsDigit) == -1
+}
+
+// Register adds checkers to DefaultRegistry. It panics if the
+// checkers collide with already registered ones.
+func Register(cs ...lint.Checker) {
+	if err := DefaultRegistry.Register(cs...); err != nil {
+		panic(err)
+	}
+}
+
+// Checkers returns the checkers registered with DefaultRegistry.
+func Checkers() []lint.Checker {
+	return DefaultRegistry.Checkers()
+}
@@ -0,0 +1,55 @@
+package lintutil
+
+import (
+	"testing"
+
+	"honnef.co/go/tools/lint"
+)
+
+type testChecker struct {
+	name   string
+	prefix string
+	ids    []string
+}
+
+func (c testChecker) Name() string     { return c.name }
+func (c testChecker) Prefix() string   { return c.prefix }
+func (testChecker) Init(*lint.Program) {}
+func (c testChecker) Checks() []lint.Check {
+	var checks []lint.Check
+	for _, id := range c.ids {
+		checks = append(checks, lint.Check{ID: id})
+	}
+	return checks
+}
+
+func TestRegistry(t *testing.T) {
+	r := &Registry{}
+	if err := r.Register(testChecker{"a", "A", []string{"A1000", "A1001"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	bad := []testChecker{
+		{"a", "B", []string{"B1000"}},
+		{"b", "A", []string{"A2000"}},
+		{"b", "B", []string{"B1000", "B1000"}},
+		{"b", "B", []string{"A1000"}},
+		{"b", "B", []string{"BX1000"}},
+		{"b", "B1", []string{"B11000"}},
+	}
+	for _, c := range bad {
+		if err := r.Register(c); err == nil {
+			t.Errorf("expected error registering %v", c)
+		}
+	}
+	if n := len(r.Checkers()); n != 1 {
+		t.Fatalf("got %d checkers, want 1", n)
+	}
+
+	if err := r.Register(testChecker{"b", "B", []string{"B1000"}}); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(r.Checkers()); n != 2 {
+		t.Fatalf("got %d checkers, want 2", n)
+	}
+}
@@ -11,10 +11,11 @@ import (
 	"errors"
 	"flag"
 	"fmt"
-	"go/build"
 	"go/token"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"regexp"
 	"runtime"
 	"runtime/debug"
@@ -28,6 +29,7 @@ import (
 	"honnef.co/go/tools/lint/lintutil/format"
 	"honnef.co/go/tools/version"
 
+	"golang.org/x/tools/go/buildutil"
 	"golang.org/x/tools/go/packages"
 )
 
@@ -40,6 +42,11 @@ func usage(name string, flags *flag.FlagSet) func() {
 		fmt.Fprintf(os.Stderr, "\t%s [flags] files... # must be a single package\n", name)
 		fmt.Fprintf(os.Stderr, "Flags:\n")
 		flags.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "Exit status:\n")
+		fmt.Fprintf(os.Stderr, "\t%d: success\n", ExitSuccess)
+		fmt.Fprintf(os.Stderr, "\t%d: too many errors or warnings\n", ExitLintFailure)
+		fmt.Fprintf(os.Stderr, "\t%d: invalid usage or failure to load packages\n", ExitInternalError)
+		fmt.Fprintf(os.Stderr, "\t%d: packages failed to compile\n", ExitCompileError)
 	}
 }
 
@@ -60,29 +67,34 @@ func parseIgnore(s string) ([]lint.Ignore, error) {
 	return out, nil
 }
 
-type versionFlag int
+// versionFlag is the minor version of Go. Its zero value means that
+// the version hasn't been set, in which case Get returns -1.
+type versionFlag struct {
+	minor int
+	set   bool
+}
 
 func (v *versionFlag) String() string {
-	return fmt.Sprintf("1.%d", *v)
+	if !v.set {
+		return ""
+	}
+	return fmt.Sprintf("1.%d", v.minor)
 }
 
 func (v *versionFlag) Set(s string) error {
-	if len(s) < 3 {
-		return errors.New("invalid Go version")
-	}
-	if s[0] != '1' {
-		return errors.New("invalid Go version")
-	}
-	if s[1] != '.' {
-		return errors.New("invalid Go version")
+	i, err := lint.ParseGoVersion(s)
+	if err != nil {
+		return err
 	}
-	i, err := strconv.Atoi(s[2:])
-	*v = versionFlag(i)
-	return err
+	*v = versionFlag{minor: i, set: true}
+	return nil
 }
 
 func (v *versionFlag) Get() interface{} {
-	return int(*v)
+	if !v.set {
+		return -1
+	}
+	return v.minor
 }
 
 type list []string
@@ -101,6 +113,10 @@ func (list *list) Set(s string) error {
 	return nil
 }
 
+func (list *list) Get() interface{} {
+	return []string(*list)
+}
+
 func FlagSet(name string) *flag.FlagSet {
 	flags := flag.NewFlagSet("", flag.ExitOnError)
 	flags.Usage = usage(name, flags)
@@ -109,10 +125,23 @@ func FlagSet(name string) *flag.FlagSet {
 	flags.Bool("tests", true, "Include tests")
 	flags.Bool("version", false, "Print version and exit")
 	flags.Bool("show-ignored", false, "Don't filter ignored problems")
-	flags.String("f", "text", "Output `format` (valid choices are 'stylish', 'text' and 'json')")
+	flags.String("f", "text", "Output `format` (valid choices are 'stylish', 'text', 'pretty', 'json', 'html' and 'sarif')")
+	flags.Var(new(outputsFlag), "o", "Additionally write problems in `format=path`, where path - is standard output and replaces -f. Can be repeated")
 	flags.String("explain", "", "Print description of `check`")
-
-	flags.Int("debug.max-concurrent-jobs", 0, "Number of jobs to run concurrently")
+	flags.Var(new(matrixFlag), "matrix", "Space-separated list of build `configurations` to lint in, each of the form GOOS/GOARCH, optionally followed by a colon and comma-separated build tags")
+	flags.Bool("workspace", false, "Lint each module below the current directory, or each module listed in its go.work file, in its own module context")
+	flags.Var(new(list), "config-root", "Comma-separated list of `files`, such as go.mod or .git, whose presence in a directory stops the inheritance of staticcheck.conf files from parent directories")
+	flags.Bool("modified", false, "Read an archive of modified files from standard input")
+	flags.Bool("codeowners", false, "Annotate problems with the owners of their files, according to the repository's CODEOWNERS file")
+	flags.Var(new(list), "owner", "Comma-separated list of `owners`; only report problems owned by one of them. Implies -codeowners")
+	flags.Bool("fix", false, "Apply the fixes of fixable problems and format the modified files")
+	flags.Bool("interactive", false, "Ask about each fix before applying it, offering to ignore the problem instead. Implies -fix")
+	flags.Bool("watch", false, "Keep running, re-linting packages affected by changes to Go files or configuration and printing new and fixed problems")
+
+	flags.Duration("job-timeout", 0, "Time budget of each check on each package, after which the check is abandoned and reported as a warning; zero means no limit. "+
+		"This only limits how long staticcheck waits: checks that don't stop on their own keep running in the background")
+
+	flags.Int("debug.max-concurrent-jobs", 0, "Number of jobs to run concurrently; zero means GOMAXPROCS")
 	flags.Bool("debug.print-stats", false, "Print debug statistics")
 	flags.String("debug.cpuprofile", "", "Write CPU profile to `file`")
 	flags.String("debug.memprofile", "", "Write memory profile to `file`")
@@ -121,15 +150,11 @@ func FlagSet(name string) *flag.FlagSet {
 	fail := list{"all"}
 	flags.Var(&checks, "checks", "Comma-separated list of `checks` to enable.")
 	flags.Var(&fail, "fail", "Comma-separated list of `checks` that can cause a non-zero exit status.")
+	flags.Int("max-errors", 0, "Maximum number of errors before exiting with a non-zero exit status, negative for unlimited")
+	flags.Int("max-warnings", -1, "Maximum number of warnings before exiting with a non-zero exit status, negative for unlimited")
+	flags.String("fail-changed-since", "", "Only count problems in files that changed since the git `revision` towards the exit status")
 
-	tags := build.Default.ReleaseTags
-	v := tags[len(tags)-1][2:]
-	version := new(versionFlag)
-	if err := version.Set(v); err != nil {
-		panic(fmt.Sprintf("internal error: %s", err))
-	}
-
-	flags.Var(version, "go", "Target Go `version` in the format '1.x'")
+	flags.Var(new(versionFlag), "go", "Target Go `version` in the format '1.x', overriding the versions in staticcheck.conf and go.mod files")
 	return flags
 }
 
@@ -154,10 +179,24 @@ func ProcessFlagSet(cs []lint.Checker, fs *flag.FlagSet) {
 	tests := fs.Lookup("tests").Value.(flag.Getter).Get().(bool)
 	goVersion := fs.Lookup("go").Value.(flag.Getter).Get().(int)
 	formatter := fs.Lookup("f").Value.(flag.Getter).Get().(string)
+	outputs := fs.Lookup("o").Value.(flag.Getter).Get().([]output)
 	printVersion := fs.Lookup("version").Value.(flag.Getter).Get().(bool)
 	showIgnored := fs.Lookup("show-ignored").Value.(flag.Getter).Get().(bool)
 	explain := fs.Lookup("explain").Value.(flag.Getter).Get().(string)
-
+	watch := fs.Lookup("watch").Value.(flag.Getter).Get().(bool)
+	modified := fs.Lookup("modified").Value.(flag.Getter).Get().(bool)
+	matrix := fs.Lookup("matrix").Value.(flag.Getter).Get().([]BuildConfig)
+	workspace := fs.Lookup("workspace").Value.(flag.Getter).Get().(bool)
+	configRoot := fs.Lookup("config-root").Value.(flag.Getter).Get().([]string)
+	maxErrors := fs.Lookup("max-errors").Value.(flag.Getter).Get().(int)
+	maxWarnings := fs.Lookup("max-warnings").Value.(flag.Getter).Get().(int)
+	changedSince := fs.Lookup("fail-changed-since").Value.(flag.Getter).Get().(string)
+	codeOwners := fs.Lookup("codeowners").Value.(flag.Getter).Get().(bool)
+	owners := fs.Lookup("owner").Value.(flag.Getter).Get().([]string)
+	interactive := fs.Lookup("interactive").Value.(flag.Getter).Get().(bool)
+	fix := fs.Lookup("fix").Value.(flag.Getter).Get().(bool) || interactive
+
+	jobTimeout := fs.Lookup("job-timeout").Value.(flag.Getter).Get().(time.Duration)
 	maxConcurrentJobs := fs.Lookup("debug.max-concurrent-jobs").Value.(flag.Getter).Get().(int)
 	printStats := fs.Lookup("debug.print-stats").Value.(flag.Getter).Get().(bool)
 	cpuProfile := fs.Lookup("debug.cpuprofile").Value.(flag.Getter).Get().(string)
@@ -166,7 +205,9 @@ func ProcessFlagSet(cs []lint.Checker, fs *flag.FlagSet) {
 	cfg := config.Config{}
 	cfg.Checks = *fs.Lookup("checks").Value.(*list)
 
+	closeOutputs := func(commit bool) error { return nil }
 	exit := func(code int) {
+		closeOutputs(false)
 		if cpuProfile != "" {
 			pprof.StopCPUProfile()
 		}
@@ -197,52 +238,156 @@ func ProcessFlagSet(cs []lint.Checker, fs *flag.FlagSet) {
 		check, ok := findCheck(cs, explain)
 		if !ok {
 			fmt.Fprintln(os.Stderr, "Couldn't find check", explain)
-			exit(1)
+			exit(ExitInternalError)
 		}
 		if check.Doc == "" {
 			fmt.Fprintln(os.Stderr, explain, "has no documentation")
-			exit(1)
+			exit(ExitInternalError)
 		}
 		fmt.Println(check.Doc)
 		exit(0)
 	}
 
-	ps, err := Lint(cs, fs.Args(), &Options{
+	docs := func() map[string]string {
+		docs := map[string]string{}
+		for _, c := range cs {
+			for _, check := range c.Checks() {
+				docs[check.ID] = check.Doc
+			}
+		}
+		return docs
+	}
+	if len(outputs) > 0 && watch {
+		fmt.Fprintln(os.Stderr, "-o can't be combined with -watch")
+		exit(ExitInternalError)
+	}
+	f, closer, err := openOutputs(formatter, outputs, docs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		exit(ExitInternalError)
+	}
+	closeOutputs = closer
+
+	var overlay map[string][]byte
+	if modified {
+		var err error
+		overlay, err = parseOverlay(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit(ExitInternalError)
+		}
+	}
+
+	fail := *fs.Lookup("fail").Value.(*list)
+	opts := &Options{
 		Tags:          strings.Fields(tags),
 		LintTests:     tests,
 		Ignores:       ignore,
 		GoVersion:     goVersion,
 		ReturnIgnored: showIgnored,
 		Config:        cfg,
+		Overlay:       overlay,
+		Matrix:        matrix,
+
+		ConfigRootMarkers: configRoot,
 
 		MaxConcurrentJobs: maxConcurrentJobs,
 		PrintStats:        printStats,
-	})
+		JobTimeout:        jobTimeout,
+	}
+
+	wd, err := os.Getwd()
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		exit(1)
+		exit(ExitInternalError)
+	}
+	if workspace {
+		opts.Workspace = wd
 	}
 
-	var f format.Formatter
-	switch formatter {
-	case "text":
-		f = format.Text{W: os.Stdout}
-	case "stylish":
-		f = &format.Stylish{W: os.Stdout}
-	case "json":
-		f = format.JSON{W: os.Stdout}
-	default:
-		fmt.Fprintf(os.Stderr, "unsupported output format %q\n", formatter)
-		exit(2)
+	// The exit policy is global, so it can only be configured by the
+	// configuration of the working directory.
+	wdcfg, err := config.Load(wd, configRoot...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		exit(ExitInternalError)
+	}
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	pol, err := exitPolicy(wdcfg, set, fail, maxErrors, maxWarnings, changedSince)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		exit(ExitInternalError)
+	}
+
+	process := func(ps []lint.Problem) []lint.Problem { return ps }
+	if codeOwners || len(owners) > 0 {
+		co, err := LoadCodeOwners(wd)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit(ExitInternalError)
+		}
+		process = func(ps []lint.Problem) []lint.Problem {
+			co.annotate(ps)
+			if len(owners) > 0 {
+				ps = filterOwners(ps, owners)
+			}
+			return ps
+		}
 	}
 
+	if fix && (watch || modified) {
+		fmt.Fprintln(os.Stderr, "-fix can't be combined with -watch or -modified")
+		exit(ExitInternalError)
+	}
+	if watch {
+		w := newWatcher(cs, fs.Args(), opts, pol.Fail, os.Stdout)
+		w.process = process
+		err := w.watch(f)
+		fmt.Fprintln(os.Stderr, err)
+		exit(ExitInternalError)
+	}
+
+	ps, err := Lint(cs, fs.Args(), opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		exit(ExitInternalError)
+	}
+
+	ps = process(ps)
+	if fix {
+		fx := newFixer(interactive, os.Stdin, os.Stderr, isTerminal(os.Stderr))
+		ps, err = fx.fix(ps)
+		if err == nil {
+			var files []string
+			files, err = fx.write()
+			for _, file := range files {
+				fmt.Fprintf(os.Stderr, "fixed %s\n", file)
+			}
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit(ExitInternalError)
+		}
+	}
+	formatProblems(f, ps, pol.Fail)
+	if err := closeOutputs(true); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		exit(ExitInternalError)
+	}
+	exit(pol.exitStatus(ps))
+}
+
+// formatProblems formats problems with f, reporting problems in
+// checks that don't match fail as warnings. Compile errors are always
+// errors. It updates the severities of the problems accordingly.
+func formatProblems(f format.Formatter, ps []lint.Problem, fail []string) {
 	var (
 		total    int
 		errors   int
 		warnings int
 	)
 
-	fail := *fs.Lookup("fail").Value.(*list)
 	var allChecks []string
 	for _, p := range ps {
 		allChecks = append(allChecks, p.Check)
@@ -251,21 +396,28 @@ func ProcessFlagSet(cs []lint.Checker, fs *flag.FlagSet) {
 	shouldExit := lint.FilterChecks(allChecks, fail)
 
 	total = len(ps)
-	for _, p := range ps {
-		if shouldExit[p.Check] {
+	for i := range ps {
+		p := &ps[i]
+		switch {
+		case p.Severity == lint.Ignored:
+		case p.Check == "compile":
+			p.Severity = lint.Error
 			errors++
-		} else {
+		case p.Severity == lint.Warning:
+			// The problem is never an error, such as an abandoned
+			// check.
+			warnings++
+		case shouldExit[p.Check]:
+			errors++
+		default:
 			p.Severity = lint.Warning
 			warnings++
 		}
-		f.Format(p)
+		f.Format(*p)
 	}
 	if f, ok := f.(format.Statter); ok {
 		f.Stats(total, errors, warnings)
 	}
-	if errors > 0 {
-		exit(1)
-	}
 }
 
 type Options struct {
@@ -274,32 +426,102 @@ type Options struct {
 	Tags          []string
 	LintTests     bool
 	Ignores       string
-	GoVersion     int
 	ReturnIgnored bool
 
+	// GoVersion is the targeted minor version of Go, or -1 to target
+	// the version of each package's configuration, go.mod or
+	// toolchain.
+	GoVersion int
+
+	// Overlay maps absolute file names to contents that replace the
+	// files on disk, for example those of unsaved editor buffers.
+	// Positions are reported against the real file names.
+	Overlay map[string][]byte
+
+	// Matrix lists build configurations to lint in. If it is empty,
+	// packages are linted once, for the host's configuration.
+	Matrix []BuildConfig
+
+	// Dir is the directory in which to run the build system. It
+	// defaults to the current directory.
+	Dir string
+	// Env lists additional environment variables for the build
+	// system, in the form key=value.
+	Env []string
+	// Workspace, if not empty, is the root of a workspace of
+	// modules. Each module in the workspace is linted separately,
+	// in its own module context.
+	Workspace string
+	// ConfigRootMarkers names files, such as go.mod or .git, whose
+	// presence in a directory stops the inheritance of configuration
+	// from parent directories.
+	ConfigRootMarkers []string
+
+	// MaxConcurrentJobs is the number of jobs to run concurrently,
+	// or GOMAXPROCS if it is zero.
 	MaxConcurrentJobs int
 	PrintStats        bool
+	// JobTimeout is the time budget of each check on each package.
+	// Zero means no limit. See lint.Linter.JobTimeout.
+	JobTimeout time.Duration
+}
+
+// parseOverlay reads an archive of modified files, in the format
+// described by buildutil.ParseOverlayArchive, and makes all file
+// names absolute.
+func parseOverlay(r io.Reader) (map[string][]byte, error) {
+	archive, err := buildutil.ParseOverlayArchive(r)
+	if err != nil {
+		return nil, err
+	}
+	overlay := make(map[string][]byte, len(archive))
+	for name, b := range archive {
+		abs, err := filepath.Abs(name)
+		if err != nil {
+			return nil, err
+		}
+		overlay[abs] = b
+	}
+	return overlay, nil
 }
 
 func Lint(cs []lint.Checker, paths []string, opt *Options) ([]lint.Problem, error) {
+	if opt == nil {
+		opt = &Options{GoVersion: -1}
+	}
+	if opt.Workspace != "" {
+		return lintWorkspace(cs, paths, opt)
+	}
+	if len(opt.Matrix) > 0 {
+		return lintMatrix(cs, paths, opt)
+	}
+	ps, _, err := lintBuildConfig(cs, paths, opt, BuildConfig{})
+	return ps, err
+}
+
+// lintBuildConfig lints packages in a single build configuration. In
+// addition to the problems, it returns the set of files that were
+// part of the matched packages in that configuration.
+func lintBuildConfig(cs []lint.Checker, paths []string, opt *Options, bc BuildConfig) ([]lint.Problem, map[string]bool, error) {
 	stats := lint.PerfStats{
 		CheckerInits: map[string]time.Duration{},
 	}
 
-	if opt == nil {
-		opt = &Options{}
-	}
 	ignores, err := parseIgnore(opt.Ignores)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
+	tags := append(append([]string(nil), opt.Tags...), bc.Tags...)
 	conf := &packages.Config{
-		Mode:  packages.LoadAllSyntax,
+		Mode:  packages.LoadAllSyntax | packages.NeedModule,
 		Tests: opt.LintTests,
 		BuildFlags: []string{
-			"-tags=" + strings.Join(opt.Tags, " "),
+			"-tags=" + strings.Join(tags, " "),
 		},
+		Dir:     opt.Dir,
+		Env:     buildEnv(opt.Env, bc),
+		Overlay: opt.Overlay,
 	}
 
 	t := time.Now()
@@ -308,14 +530,18 @@ func Lint(cs []lint.Checker, paths []string, opt *Options) ([]lint.Problem, erro
 	}
 	pkgs, err := packages.Load(conf, paths...)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	stats.PackageLoading = time.Since(t)
 	runtime.GC()
 
+	files := map[string]bool{}
 	var problems []lint.Problem
 	workingPkgs := make([]*packages.Package, 0, len(pkgs))
 	for _, pkg := range pkgs {
+		for _, f := range pkg.GoFiles {
+			files[f] = true
+		}
 		if pkg.IllTyped {
 			problems = append(problems, compileErrors(pkg)...)
 		} else {
@@ -324,7 +550,7 @@ func Lint(cs []lint.Checker, paths []string, opt *Options) ([]lint.Problem, erro
 	}
 
 	if len(workingPkgs) == 0 {
-		return problems, nil
+		return problems, files, nil
 	}
 
 	l := &lint.Linter{
@@ -333,13 +559,17 @@ func Lint(cs []lint.Checker, paths []string, opt *Options) ([]lint.Problem, erro
 		GoVersion:     opt.GoVersion,
 		ReturnIgnored: opt.ReturnIgnored,
 		Config:        opt.Config,
+		Overlay:       opt.Overlay,
+
+		ConfigRootMarkers: opt.ConfigRootMarkers,
 
 		MaxConcurrentJobs: opt.MaxConcurrentJobs,
 		PrintStats:        opt.PrintStats,
+		JobTimeout:        opt.JobTimeout,
 	}
 	problems = append(problems, l.Lint(workingPkgs, &stats)...)
 
-	return problems, nil
+	return problems, files, nil
 }
 
 var posRe = regexp.MustCompile(`^(.+?):(\d+)(?::(\d+)?)?$`)
@@ -392,3 +622,13 @@ func ProcessArgs(name string, cs []lint.Checker, args []string) {
 
 	ProcessFlagSet(cs, flags)
 }
+
+// isTerminal reports whether f is a terminal that colored output
+// may be written to.
+func isTerminal(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,273 @@
+package lintutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+	"honnef.co/go/tools/lint"
+)
+
+// VetConfig describes a single package to check, as passed by go vet
+// to tools specified with -vettool. It is the configuration of the
+// unitchecker protocol, see golang.org/x/tools/go/analysis/unitchecker.
+type VetConfig struct {
+	ID                        string // e.g. "fmt [fmt.test]"
+	Compiler                  string
+	Dir                       string
+	ImportPath                string
+	GoVersion                 string // e.g. "go1.21"
+	GoFiles                   []string
+	NonGoFiles                []string
+	IgnoredFiles              []string
+	ModulePath                string
+	ModuleVersion             string
+	Module                    *VetModule        // used by newer versions of go vet instead of ModulePath
+	ImportMap                 map[string]string // maps import paths to package paths
+	PackageFile               map[string]string // maps package paths to export data files
+	Standard                  map[string]bool
+	PackageVetx               map[string]string // maps package paths to fact files
+	VetxOnly                  bool              // only compute facts
+	VetxOutput                string            // where to write the facts
+	Stdout                    string            // if set, where to write output that would go to standard output
+	SucceedOnTypecheckFailure bool
+}
+
+// VetModule describes the module of a package.
+type VetModule struct {
+	Path      string
+	Version   string
+	GoVersion string
+}
+
+// ReadVetConfig reads the configuration that go vet passes to vet
+// tools.
+func ReadVetConfig(path string) (*VetConfig, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &VetConfig{}
+	if err := json.Unmarshal(b, cfg); err != nil {
+		return nil, fmt.Errorf("couldn't decode vet configuration %s: %s", path, err)
+	}
+	if len(cfg.GoFiles) == 0 {
+		// go vet doesn't run tools on packages without Go files
+		return nil, fmt.Errorf("package %s has no Go files", cfg.ImportPath)
+	}
+	if cfg.ModulePath == "" && cfg.Module != nil {
+		cfg.ModulePath = cfg.Module.Path
+		cfg.ModuleVersion = cfg.Module.Version
+	}
+	return cfg, nil
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
+
+// Vet lints the package described by cfg, using export data for its
+// dependencies and passing facts between packages through go vet's
+// fact files. Unless cfg.VetxOnly is set, it returns the problems in
+// the package.
+func Vet(cs []lint.Checker, cfg *VetConfig, opt *Options) ([]lint.Problem, error) {
+	if opt == nil {
+		opt = &Options{GoVersion: -1}
+	}
+	pkg, err := loadVetPackage(cfg)
+	if err != nil {
+		if cfg.SucceedOnTypecheckFailure {
+			// The compiler will report the errors
+			return nil, writeVetFacts(cfg.VetxOutput, lint.NewFacts())
+		}
+		return nil, err
+	}
+
+	facts := lint.NewFacts()
+	for _, path := range cfg.PackageVetx {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) == 0 {
+			continue
+		}
+		if err := facts.Decode(b); err != nil {
+			return nil, fmt.Errorf("couldn't decode facts %s: %s", path, err)
+		}
+	}
+
+	ignores, err := parseIgnore(opt.Ignores)
+	if err != nil {
+		return nil, err
+	}
+	l := &lint.Linter{
+		Checkers:      cs,
+		Ignores:       ignores,
+		GoVersion:     opt.GoVersion,
+		ReturnIgnored: opt.ReturnIgnored,
+		Config:        opt.Config,
+		Facts:         facts,
+		FactsOnly:     cfg.VetxOnly,
+
+		ConfigRootMarkers: opt.ConfigRootMarkers,
+
+		MaxConcurrentJobs: opt.MaxConcurrentJobs,
+		PrintStats:        opt.PrintStats,
+		JobTimeout:        opt.JobTimeout,
+	}
+	problems := l.Lint([]*packages.Package{pkg}, nil)
+
+	// The facts of our dependencies are passed on, too, because go
+	// vet only gives us the fact files of direct dependencies.
+	if err := writeVetFacts(cfg.VetxOutput, facts); err != nil {
+		return nil, err
+	}
+	return problems, nil
+}
+
+func writeVetFacts(path string, facts *lint.Facts) error {
+	if path == "" {
+		return nil
+	}
+	b, err := facts.Encode()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0666)
+}
+
+// loadVetPackage parses and type-checks the package described by
+// cfg. Its dependencies are loaded from export data and only have
+// types.
+func loadVetPackage(cfg *VetConfig) (*packages.Package, error) {
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range cfg.GoFiles {
+		f, err := parser.ParseFile(fset, name, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	compiler := importer.ForCompiler(fset, cfg.Compiler, func(path string) (io.ReadCloser, error) {
+		file, ok := cfg.PackageFile[path]
+		if !ok {
+			return nil, fmt.Errorf("no export data for package %q", path)
+		}
+		return os.Open(file)
+	})
+	imp := importerFunc(func(importPath string) (*types.Package, error) {
+		path, ok := cfg.ImportMap[importPath]
+		if !ok {
+			return nil, fmt.Errorf("can't resolve import %q", importPath)
+		}
+		return compiler.Import(path)
+	})
+
+	tc := &types.Config{
+		Importer: imp,
+		Sizes:    types.SizesFor(cfg.Compiler, build.Default.GOARCH),
+	}
+	info := &types.Info{
+		Types:      map[ast.Expr]types.TypeAndValue{},
+		Defs:       map[*ast.Ident]types.Object{},
+		Uses:       map[*ast.Ident]types.Object{},
+		Implicits:  map[ast.Node]types.Object{},
+		Scopes:     map[ast.Node]*types.Scope{},
+		Selections: map[*ast.SelectorExpr]*types.Selection{},
+	}
+	tpkg, err := tc.Check(cfg.ImportPath, fset, files, info)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := map[*types.Package]*packages.Package{}
+	var dep func(tpkg *types.Package) *packages.Package
+	dep = func(tpkg *types.Package) *packages.Package {
+		if p, ok := deps[tpkg]; ok {
+			return p
+		}
+		p := &packages.Package{
+			ID:      tpkg.Path(),
+			Name:    tpkg.Name(),
+			PkgPath: tpkg.Path(),
+			Types:   tpkg,
+			Fset:    fset,
+			Imports: map[string]*packages.Package{},
+		}
+		deps[tpkg] = p
+		// We don't know how dependencies spelled their imports,
+		// so we key them by package path.
+		for _, imp := range tpkg.Imports() {
+			p.Imports[imp.Path()] = dep(imp)
+		}
+		return p
+	}
+
+	pkg := &packages.Package{
+		ID:              cfg.ID,
+		Name:            tpkg.Name(),
+		PkgPath:         cfg.ImportPath,
+		GoFiles:         cfg.GoFiles,
+		CompiledGoFiles: cfg.GoFiles,
+		OtherFiles:      cfg.NonGoFiles,
+		Syntax:          files,
+		Types:           tpkg,
+		TypesInfo:       info,
+		TypesSizes:      tc.Sizes,
+		Fset:            fset,
+		Imports:         map[string]*packages.Package{},
+	}
+	for path, imp := range importsBySource(files, cfg.ImportMap, tpkg.Imports()) {
+		pkg.Imports[path] = dep(imp)
+	}
+	if cfg.ModulePath != "" {
+		pkg.Module = &packages.Module{
+			Path:      cfg.ModulePath,
+			Version:   cfg.ModuleVersion,
+			GoVersion: strings.TrimPrefix(cfg.GoVersion, "go"),
+		}
+	}
+	return pkg, nil
+}
+
+// importsBySource maps the import paths as written in files to the
+// packages among imports that importMap resolves them to, such as
+// "golang.org/x/net/idna" to "vendor/golang.org/x/net/idna". This is
+// how go/packages keys imports.
+func importsBySource(files []*ast.File, importMap map[string]string, imports []*types.Package) map[string]*types.Package {
+	byPath := map[string]*types.Package{}
+	for _, imp := range imports {
+		byPath[imp.Path()] = imp
+	}
+	out := map[string]*types.Package{}
+	for _, f := range files {
+		for _, spec := range f.Imports {
+			importPath, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+			path, ok := importMap[importPath]
+			if !ok {
+				path = importPath
+			}
+			if imp, ok := byPath[path]; ok {
+				out[importPath] = imp
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,76 @@
+package lintutil
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadVetConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "staticcheck-vet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(data string) string {
+		path := filepath.Join(dir, "vet.cfg")
+		if err := ioutil.WriteFile(path, []byte(data), 0666); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	cfg, err := ReadVetConfig(write(`{
+	"ID": "example.com/a",
+	"ImportPath": "example.com/a",
+	"GoFiles": ["/src/a/a.go"],
+	"Module": {"Path": "example.com", "Version": "v1.0.0"},
+	"VetxOnly": true
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ModulePath != "example.com" || cfg.ModuleVersion != "v1.0.0" {
+		t.Errorf("got module %q %q, want %q %q", cfg.ModulePath, cfg.ModuleVersion, "example.com", "v1.0.0")
+	}
+	if !cfg.VetxOnly {
+		t.Error("VetxOnly wasn't set")
+	}
+
+	if _, err := ReadVetConfig(write(`{"ImportPath": "example.com/b"}`)); err == nil {
+		t.Error("expected an error for a package without Go files")
+	}
+	if _, err := ReadVetConfig(write(`{`)); err == nil {
+		t.Error("expected an error for a malformed configuration")
+	}
+}
+
+func TestImportsBySource(t *testing.T) {
+	const src = `package a
+
+import (
+	"fmt"
+	idna "golang.org/x/net/idna"
+)
+`
+	f, err := parser.ParseFile(token.NewFileSet(), "a.go", src, parser.ImportsOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmtPkg := types.NewPackage("fmt", "fmt")
+	idnaPkg := types.NewPackage("vendor/golang.org/x/net/idna", "idna")
+	importMap := map[string]string{
+		"fmt":                   "fmt",
+		"golang.org/x/net/idna": "vendor/golang.org/x/net/idna",
+	}
+	got := importsBySource([]*ast.File{f}, importMap, []*types.Package{fmtPkg, idnaPkg})
+	if len(got) != 2 || got["fmt"] != fmtPkg || got["golang.org/x/net/idna"] != idnaPkg {
+		t.Errorf("got imports %v", got)
+	}
+}
@@ -0,0 +1,406 @@
+package lintutil
+
+import (
+	"bytes"
+	"fmt"
+	"go/build"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"honnef.co/go/tools/lint"
+	"honnef.co/go/tools/lint/lintutil/format"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const watchInterval = time.Second
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// watcher repeatedly lints a set of packages, re-linting only those
+// packages that were affected by changes to Go files or
+// configuration files.
+type watcher struct {
+	cs    []lint.Checker
+	paths []string
+	opt   *Options
+	fail  []string
+	w     io.Writer
+
+	// dirs contains the directories of all packages matched by
+	// paths.
+	dirs map[string]bool
+	// roots contains the directories below which recursive patterns
+	// such as ./... match packages. They are scanned for packages
+	// that have been added since the last load.
+	roots map[string]bool
+	// pkgs maps directories to the import paths of the packages
+	// they contain.
+	pkgs map[string][]string
+	// importers maps import paths to the directories of packages
+	// that directly import them.
+	importers map[string]map[string]bool
+
+	// process, if not nil, post-processes the problems of each run.
+	process func([]lint.Problem) []lint.Problem
+
+	stamps map[string]fileStamp
+	// problems maps package directories to the problems found in
+	// them. Problems without a file are stored under the empty
+	// string.
+	problems map[string][]lint.Problem
+}
+
+func newWatcher(cs []lint.Checker, paths []string, opt *Options, fail []string, w io.Writer) *watcher {
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	return &watcher{
+		cs:       cs,
+		paths:    paths,
+		opt:      opt,
+		fail:     fail,
+		w:        w,
+		problems: map[string][]lint.Problem{},
+	}
+}
+
+// loadGraph determines the set of packages matched by the watcher's
+// patterns and their import relationships.
+func (w *watcher) loadGraph() error {
+	conf := &packages.Config{
+		Mode:  packages.LoadImports,
+		Tests: w.opt.LintTests,
+		BuildFlags: []string{
+			"-tags=" + strings.Join(w.opt.Tags, " "),
+		},
+		Overlay: w.opt.Overlay,
+	}
+	pkgs, err := packages.Load(conf, w.paths...)
+	if err != nil {
+		return err
+	}
+	w.dirs = map[string]bool{}
+	w.roots = map[string]bool{}
+	w.pkgs = map[string][]string{}
+	w.importers = map[string]map[string]bool{}
+	for _, path := range w.paths {
+		if !strings.HasSuffix(path, "/...") || !build.IsLocalImport(path) && !filepath.IsAbs(path) {
+			continue
+		}
+		if root, err := filepath.Abs(strings.TrimSuffix(path, "/...")); err == nil {
+			w.roots[root] = true
+		}
+	}
+	for _, pkg := range pkgs {
+		files := pkg.GoFiles
+		if len(files) == 0 {
+			files = pkg.CompiledGoFiles
+		}
+		if len(files) == 0 {
+			continue
+		}
+		dir := filepath.Dir(files[0])
+		w.dirs[dir] = true
+		if root, ok := w.importRoot(pkg.PkgPath, dir); ok {
+			w.roots[root] = true
+		}
+		w.pkgs[dir] = append(w.pkgs[dir], pkg.PkgPath)
+		for _, imp := range pkg.Imports {
+			m := w.importers[imp.PkgPath]
+			if m == nil {
+				m = map[string]bool{}
+				w.importers[imp.PkgPath] = m
+			}
+			m[dir] = true
+		}
+	}
+	return nil
+}
+
+// importRoot returns the directory that corresponds to the prefix of
+// a recursive import path pattern, such as example.com/foo/..., that
+// matches the package path in dir.
+func (w *watcher) importRoot(path, dir string) (string, bool) {
+	for _, pattern := range w.paths {
+		if !strings.HasSuffix(pattern, "/...") || build.IsLocalImport(pattern) || filepath.IsAbs(pattern) {
+			continue
+		}
+		prefix := strings.TrimSuffix(pattern, "/...")
+		if path != prefix && !strings.HasPrefix(path, prefix+"/") {
+			continue
+		}
+		rel := filepath.FromSlash(strings.TrimPrefix(path, prefix))
+		if !strings.HasSuffix(dir, rel) {
+			continue
+		}
+		return strings.TrimSuffix(dir, rel), true
+	}
+	return "", false
+}
+
+// scan records the modification times of all Go files in watched
+// directories and below the roots of recursive patterns, as well as
+// of all configuration files that apply to them.
+func (w *watcher) scan() map[string]fileStamp {
+	stamps := map[string]fileStamp{}
+	dirs := map[string]bool{}
+	for dir := range w.dirs {
+		dirs[dir] = true
+	}
+	for root := range w.roots {
+		filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+			if err != nil || !fi.IsDir() {
+				return nil
+			}
+			name := fi.Name()
+			if path != root && (name == "testdata" || name == "vendor" ||
+				strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				// Directories that the go tool ignores when
+				// matching patterns.
+				return filepath.SkipDir
+			}
+			dirs[path] = true
+			return nil
+		})
+	}
+
+	seen := map[string]bool{}
+	for dir := range dirs {
+		fis, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		hasGo := false
+		for _, fi := range fis {
+			if fi.IsDir() || !strings.HasSuffix(fi.Name(), ".go") {
+				continue
+			}
+			hasGo = true
+			stamps[filepath.Join(dir, fi.Name())] = fileStamp{fi.ModTime(), fi.Size()}
+		}
+		if !hasGo {
+			continue
+		}
+
+		// Configuration is inherited from parent directories.
+		for d := dir; !seen[d]; {
+			seen[d] = true
+			path := filepath.Join(d, "staticcheck.conf")
+			if fi, err := os.Stat(path); err == nil {
+				stamps[path] = fileStamp{fi.ModTime(), fi.Size()}
+			}
+			nd := filepath.Dir(d)
+			if nd == d {
+				break
+			}
+			d = nd
+		}
+	}
+	return stamps
+}
+
+// diffStamps returns the files that were added, removed or
+// modified between two scans.
+func diffStamps(old, new map[string]fileStamp) []string {
+	var changed []string
+	for path, st := range new {
+		if ost, ok := old[path]; !ok || ost != st {
+			changed = append(changed, path)
+		}
+	}
+	for path := range old {
+		if _, ok := new[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	return changed
+}
+
+// changedDirs returns the directories containing the changed files.
+// A changed configuration file affects its directory and all
+// directories below it.
+func (w *watcher) changedDirs(changed map[string]bool) map[string]bool {
+	out := map[string]bool{}
+	for path := range changed {
+		dir := filepath.Dir(path)
+		if filepath.Base(path) != "staticcheck.conf" {
+			out[dir] = true
+			continue
+		}
+		for d := range w.dirs {
+			if d == dir || strings.HasPrefix(d, dir+string(filepath.Separator)) {
+				out[d] = true
+			}
+		}
+	}
+	return out
+}
+
+// affected extends a set of changed directories with the directories
+// of all packages that transitively import packages in them.
+func (w *watcher) affected(dirs map[string]bool) map[string]bool {
+	out := map[string]bool{}
+	var queue []string
+	for dir := range dirs {
+		if w.dirs[dir] {
+			out[dir] = true
+			queue = append(queue, dir)
+		}
+	}
+	for len(queue) > 0 {
+		dir := queue[0]
+		queue = queue[1:]
+		for _, path := range w.pkgs[dir] {
+			for importer := range w.importers[path] {
+				if !out[importer] {
+					out[importer] = true
+					queue = append(queue, importer)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// lint lints the packages in dirs and replaces their previously
+// recorded problems. It returns the problems before and after.
+func (w *watcher) lint(dirs map[string]bool) (before, after []lint.Problem, err error) {
+	var patterns []string
+	for dir := range dirs {
+		patterns = append(patterns, dir)
+	}
+	sort.Strings(patterns)
+	var ps []lint.Problem
+	if len(patterns) > 0 {
+		ps, err = Lint(w.cs, patterns, w.opt)
+		if err != nil {
+			return nil, nil, err
+		}
+		if w.process != nil {
+			ps = w.process(ps)
+		}
+	}
+
+	for _, p := range w.problems {
+		before = append(before, p...)
+	}
+	sortProblems(before)
+	for dir := range w.problems {
+		if dirs[dir] || !w.dirs[dir] {
+			// Packages that were re-linted, or that no longer
+			// exist.
+			delete(w.problems, dir)
+		}
+	}
+	for _, p := range ps {
+		dir := ""
+		if p.Position.Filename != "" {
+			dir = filepath.Dir(p.Position.Filename)
+			if !dirs[dir] {
+				// We've already recorded problems in unaffected
+				// packages.
+				continue
+			}
+		}
+		w.problems[dir] = append(w.problems[dir], p)
+	}
+	for _, p := range w.problems {
+		after = append(after, p...)
+	}
+	sortProblems(after)
+	return before, after, nil
+}
+
+// watch lints the packages once, formatting all problems with f, and
+// then keeps watching for changes, printing the problems that were
+// introduced or fixed by each change. It only returns if it failed to
+// load packages.
+func (w *watcher) watch(f format.Formatter) error {
+	if err := w.loadGraph(); err != nil {
+		return err
+	}
+	w.stamps = w.scan()
+	_, ps, err := w.lint(w.dirs)
+	if err != nil {
+		return err
+	}
+	formatProblems(f, ps, w.fail)
+
+	// changed accumulates the files that changed until they have
+	// been linted successfully.
+	changed := map[string]bool{}
+	for {
+		time.Sleep(watchInterval)
+		stamps := w.scan()
+		for _, path := range diffStamps(w.stamps, stamps) {
+			changed[path] = true
+		}
+		// Changes made after this scan will be picked up by the
+		// next one.
+		w.stamps = stamps
+		if len(changed) == 0 {
+			continue
+		}
+
+		// The set of packages and their imports may have changed,
+		// too.
+		if err := w.loadGraph(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			continue
+		}
+		before, after, err := w.lint(w.affected(w.changedDirs(changed)))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			continue
+		}
+		changed = map[string]bool{}
+		w.printDiff(before, after)
+	}
+}
+
+func (w *watcher) printDiff(before, after []lint.Problem) {
+	c := Compare(before, after)
+	added, fixed := c.Introduced, c.Fixed
+
+	var buf bytes.Buffer
+	text := format.Text{W: &buf}
+	fmt.Fprintf(&buf, "--- %s: %d new, %d fixed, %d total\n",
+		time.Now().Format("15:04:05"), len(added), len(fixed), len(after))
+	for _, p := range added {
+		buf.WriteString("+ ")
+		text.Format(p)
+	}
+	for _, p := range fixed {
+		buf.WriteString("- ")
+		text.Format(p)
+	}
+	w.w.Write(buf.Bytes())
+}
+
+func sortProblems(ps []lint.Problem) {
+	sort.Slice(ps, func(i, j int) bool {
+		pi, pj := ps[i].Position, ps[j].Position
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		if pi.Line != pj.Line {
+			return pi.Line < pj.Line
+		}
+		if pi.Column != pj.Column {
+			return pi.Column < pj.Column
+		}
+		if ps[i].Text != ps[j].Text {
+			return ps[i].Text < ps[j].Text
+		}
+		return ps[i].Check < ps[j].Check
+	})
+}
@@ -0,0 +1,114 @@
+package lintutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWatcherAffected(t *testing.T) {
+	w := &watcher{
+		dirs: map[string]bool{"/a": true, "/a/b": true, "/c": true, "/d": true},
+		pkgs: map[string][]string{
+			"/a":   {"a"},
+			"/a/b": {"a/b"},
+			"/c":   {"c"},
+			"/d":   {"d"},
+		},
+		importers: map[string]map[string]bool{
+			"a/b": {"/c": true},
+			"c":   {"/d": true},
+		},
+	}
+
+	now := time.Now()
+	old := map[string]fileStamp{
+		"/a/b/b.go":           {now, 1},
+		"/a/staticcheck.conf": {now, 1},
+		"/d/d.go":             {now, 1},
+	}
+
+	tests := []struct {
+		new  map[string]fileStamp
+		want map[string]bool
+	}{
+		{
+			map[string]fileStamp{"/a/b/b.go": {now, 2}, "/a/staticcheck.conf": {now, 1}, "/d/d.go": {now, 1}},
+			map[string]bool{"/a/b": true, "/c": true, "/d": true},
+		},
+		{
+			map[string]fileStamp{"/a/b/b.go": {now, 1}, "/a/staticcheck.conf": {now, 2}, "/d/d.go": {now, 1}},
+			map[string]bool{"/a": true, "/a/b": true, "/c": true, "/d": true},
+		},
+		{
+			map[string]fileStamp{"/a/b/b.go": {now, 1}, "/a/staticcheck.conf": {now, 1}},
+			map[string]bool{"/d": true},
+		},
+	}
+	for i, tt := range tests {
+		changed := map[string]bool{}
+		for _, path := range diffStamps(old, tt.new) {
+			changed[path] = true
+		}
+		got := w.affected(w.changedDirs(changed))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%d: got %v, want %v", i, got, tt.want)
+		}
+	}
+}
+
+func TestWatcherImportRoot(t *testing.T) {
+	w := &watcher{paths: []string{"./...", "example.com/a/...", "example.com/c"}}
+	tests := []struct {
+		path, dir string
+		want      string
+	}{
+		{"example.com/a", "/src/a", "/src/a"},
+		{"example.com/a/b/c", "/src/a/b/c", "/src/a"},
+		{"example.com/ab", "/src/ab", ""},
+		{"example.com/c", "/src/c", ""},
+	}
+	for _, tt := range tests {
+		got, ok := w.importRoot(tt.path, filepath.FromSlash(tt.dir))
+		if ok != (tt.want != "") || got != filepath.FromSlash(tt.want) {
+			t.Errorf("importRoot(%q, %q) = %q, %t, want %q", tt.path, tt.dir, got, ok, tt.want)
+		}
+	}
+}
+
+func TestWatcherScanNewPackages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "staticcheck-watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name string) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte("package p\n"), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a/a.go")
+	write("staticcheck.conf")
+
+	w := &watcher{
+		dirs:  map[string]bool{filepath.Join(dir, "a"): true},
+		roots: map[string]bool{dir: true},
+	}
+	old := w.scan()
+	write("b/b.go")
+	write("testdata/t.go")
+	write(".hidden/h.go")
+
+	changed := diffStamps(old, w.scan())
+	want := []string{filepath.Join(dir, "b", "b.go")}
+	if !reflect.DeepEqual(changed, want) {
+		t.Errorf("got changed files %v, want %v", changed, want)
+	}
+}
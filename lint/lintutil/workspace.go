@@ -0,0 +1,128 @@
+package lintutil
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"honnef.co/go/tools/lint"
+)
+
+// findModules returns the directories of all modules in the
+// workspace rooted at root. If root contains a go.work file, the
+// modules it uses make up the workspace. Otherwise, all directories
+// below root that contain a go.mod file are modules, skipping the
+// same directories that the go command ignores for the ./... pattern.
+func findModules(root string) ([]string, error) {
+	if f, err := os.Open(filepath.Join(root, "go.work")); err == nil {
+		defer f.Close()
+		dirs, err := parseGoWork(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", filepath.Join(root, "go.work"), err)
+		}
+		for i, dir := range dirs {
+			if !filepath.IsAbs(dir) {
+				dirs[i] = filepath.Join(root, dir)
+			}
+		}
+		return dirs, nil
+	}
+
+	var dirs []string
+	err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() {
+			return nil
+		}
+		name := fi.Name()
+		if path != root && (name == "vendor" || name == "testdata" ||
+			strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+			return filepath.SkipDir
+		}
+		if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	return dirs, err
+}
+
+// parseGoWork returns the directories named by use directives in a
+// go.work file.
+func parseGoWork(r io.Reader) ([]string, error) {
+	var (
+		dirs  []string
+		inUse bool
+	)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.Index(line, "//"); i != -1 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if inUse {
+			if line == ")" {
+				inUse = false
+				continue
+			}
+		} else {
+			if !strings.HasPrefix(line, "use ") && !strings.HasPrefix(line, "use\t") && !strings.HasPrefix(line, "use(") {
+				continue
+			}
+			line = strings.TrimSpace(line[len("use"):])
+			if line == "(" {
+				inUse = true
+				continue
+			}
+		}
+		dir := line
+		if strings.HasPrefix(dir, `"`) || strings.HasPrefix(dir, "`") {
+			var err error
+			dir, err = strconv.Unquote(dir)
+			if err != nil {
+				return nil, fmt.Errorf("malformed use directive %q", sc.Text())
+			}
+		} else if strings.ContainsAny(dir, " \t") {
+			return nil, fmt.Errorf("malformed use directive %q", sc.Text())
+		}
+		dirs = append(dirs, filepath.FromSlash(dir))
+	}
+	return dirs, sc.Err()
+}
+
+// lintWorkspace lints each module in the workspace opt.Workspace in
+// its own module context. Patterns are interpreted relative to each
+// module's directory. The problems of all modules are merged into a
+// single, sorted list.
+func lintWorkspace(cs []lint.Checker, paths []string, opt *Options) ([]lint.Problem, error) {
+	dirs, err := findModules(opt.Workspace)
+	if err != nil {
+		return nil, err
+	}
+	var out []lint.Problem
+	for _, dir := range dirs {
+		mopt := *opt
+		mopt.Workspace = ""
+		mopt.Dir = dir
+		// Load each module on its own, not as part of the workspace
+		// described by a go.work file.
+		mopt.Env = append(append([]string(nil), opt.Env...), "GOWORK=off")
+		ps, err := Lint(cs, paths, &mopt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", dir, err)
+		}
+		out = append(out, ps...)
+	}
+	sortProblems(out)
+	return out, nil
+}
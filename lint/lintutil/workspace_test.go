@@ -0,0 +1,35 @@
+package lintutil
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGoWork(t *testing.T) {
+	const work = `go 1.18
+
+use ./tools // the tools module
+
+use (
+	./a
+	"./b c"
+	// ./d
+)
+
+replace example.com/x => ./x
+`
+	dirs, err := parseGoWork(strings.NewReader(work))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.FromSlash("./tools"),
+		filepath.FromSlash("./a"),
+		filepath.FromSlash("./b c"),
+	}
+	if !reflect.DeepEqual(dirs, want) {
+		t.Errorf("got %q, want %q", dirs, want)
+	}
+}
@@ -0,0 +1,139 @@
+package lint
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/tools/go/packages"
+	"honnef.co/go/tools/ssa"
+)
+
+// pipelineChecker records the order in which its checks run and in
+// which its functions are prepared. Its Init waits for its NeedsTypes
+// check, which could never run if checks only started after Init.
+type pipelineChecker struct {
+	typesDone chan struct{}
+
+	mu       sync.Mutex
+	prepared map[string]bool
+	events   []string
+}
+
+func (*pipelineChecker) Name() string   { return "pipeline" }
+func (*pipelineChecker) Prefix() string { return "TEST" }
+
+func (c *pipelineChecker) Init(*Program) {
+	select {
+	case <-c.typesDone:
+	case <-time.After(10 * time.Second):
+	}
+	c.event("init")
+}
+
+func (c *pipelineChecker) PrepareFunction(fn *ssa.Function) {
+	c.mu.Lock()
+	c.prepared[fn.Name()] = true
+	c.mu.Unlock()
+}
+
+func (c *pipelineChecker) event(s string) {
+	c.mu.Lock()
+	c.events = append(c.events, s)
+	c.mu.Unlock()
+}
+
+func (c *pipelineChecker) Checks() []Check {
+	return []Check{
+		{ID: "TEST1000", Needs: NeedsTypes, Fn: func(j *Job) {
+			c.event("types")
+			close(c.typesDone)
+		}},
+		{ID: "TEST1001", Needs: NeedsSSA, Fn: func(j *Job) {
+			names := map[string]bool{}
+			for _, fn := range j.Pkg.InitialFunctions {
+				names[fn.Name()] = true
+			}
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			for _, name := range []string{"F", "M", "F$1", "init"} {
+				if !names[name] {
+					c.events = append(c.events, "missing "+name)
+				}
+			}
+			for _, name := range []string{"F", "M", "F$1"} {
+				if !c.prepared[name] {
+					c.events = append(c.events, "unprepared "+name)
+				}
+			}
+			c.events = append(c.events, "ssa")
+		}},
+		{ID: "TEST1002", Fn: func(j *Job) { c.event("program") }},
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	const src = `package a
+
+type T struct{}
+
+func (T) M() {}
+
+func F() func() { return func() {} }
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "a.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{
+		Types:      map[ast.Expr]types.TypeAndValue{},
+		Defs:       map[*ast.Ident]types.Object{},
+		Uses:       map[*ast.Ident]types.Object{},
+		Implicits:  map[ast.Node]types.Object{},
+		Scopes:     map[ast.Node]*types.Scope{},
+		Selections: map[*ast.SelectorExpr]*types.Selection{},
+	}
+	tpkg, err := (&types.Config{}).Check("a", fset, []*ast.File{f}, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := &packages.Package{
+		ID:        "a",
+		Name:      "a",
+		PkgPath:   "a",
+		Fset:      fset,
+		Syntax:    []*ast.File{f},
+		Types:     tpkg,
+		TypesInfo: info,
+		Imports:   map[string]*packages.Package{},
+	}
+
+	c := &pipelineChecker{typesDone: make(chan struct{}), prepared: map[string]bool{}}
+	l := &Linter{Checkers: []Checker{c}}
+	l.Lint([]*packages.Package{pkg}, nil)
+
+	// The NeedsSSA check may run before or after Init.
+	var got []string
+	for _, ev := range c.events {
+		if ev != "ssa" {
+			got = append(got, ev)
+		}
+	}
+	want := []string{"types", "init", "program"}
+	if len(got) != len(want) {
+		t.Fatalf("got events %v, want %v plus ssa", c.events, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got events %v, want %v plus ssa", c.events, want)
+		}
+	}
+	if len(got) == len(c.events) {
+		t.Errorf("NeedsSSA check didn't run: %v", c.events)
+	}
+}
@@ -0,0 +1,60 @@
+package lint
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestRunJobTimeout(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "a.go", "package a\n", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := &Pkg{Package: &packages.Package{PkgPath: "a", Fset: fset, Syntax: []*ast.File{f}}}
+	l := &Linter{JobTimeout: 10 * time.Millisecond}
+
+	block := make(chan struct{})
+	defer close(block)
+	tests := []struct {
+		name      string
+		fn        Func
+		abandoned bool
+	}{
+		{"fast", func(j *Job) {}, false},
+		// uses the job's facts, which the runner must pass on
+		{"facts", func(j *Job) {
+			var fact testFact
+			j.Facts.ImportPackageFact(types.NewPackage("a", "a"), &fact)
+		}, false},
+		// ignores its context and is abandoned by the runner
+		{"stuck", func(j *Job) { <-block }, true},
+		// returns early once its context is done
+		{"cooperative", func(j *Job) {
+			<-j.Context().Done()
+			j.Abandon()
+		}, true},
+	}
+	for _, tt := range tests {
+		check := Check{ID: "TEST1000", Fn: tt.fn}
+		j := &Job{Pkg: pkg, Facts: NewFacts(), check: check}
+		l.runJob(check, j)
+		if j.abandoned != tt.abandoned {
+			t.Errorf("%s: got abandoned = %t, want %t", tt.name, j.abandoned, tt.abandoned)
+		}
+		if tt.abandoned {
+			if len(j.problems) != 1 || !strings.Contains(j.problems[0].Text, "abandoned") || j.problems[0].Severity != Warning {
+				t.Errorf("%s: got problems %v", tt.name, j.problems)
+			}
+		} else if len(j.problems) != 0 {
+			t.Errorf("%s: got problems %v", tt.name, j.problems)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package lint
+
+import (
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestMergeVariants(t *testing.T) {
+	pos := func(line int) token.Position { return token.Position{Filename: "a.go", Line: line, Column: 1} }
+	p := func(line int, check, text, variant string) Problem {
+		return Problem{Position: pos(line), Check: check, Text: text, Variants: []string{variant}}
+	}
+	const (
+		pkg  = "example.com/a"
+		test = "example.com/a [example.com/a.test]"
+	)
+	ps := []Problem{
+		p(1, "SA4006", "x is never used", pkg),
+		p(1, "SA4006", "x is never used", test),
+		// two problems at the same position in each variant
+		p(2, "SA5000", "first", pkg),
+		p(2, "SA5000", "second", pkg),
+		p(2, "SA5000", "first", test),
+		p(2, "SA5000", "second", test),
+		// problems whose messages differ between variants are kept
+		// apart
+		p(3, "SA1019", "a is deprecated", pkg),
+		p(3, "SA1019", "a is deprecated: use b", test),
+		p(4, "S1000", "only in tests", test),
+	}
+	got := mergeVariants(ps)
+
+	type result struct {
+		line     int
+		text     string
+		variants string
+	}
+	want := []result{
+		{1, "x is never used", pkg + "," + test},
+		{2, "first", pkg + "," + test},
+		{2, "second", pkg + "," + test},
+		{3, "a is deprecated", pkg},
+		{3, "a is deprecated: use b", test},
+		{4, "only in tests", test},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d problems, want %d: %v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		g := result{got[i].Position.Line, got[i].Text, strings.Join(got[i].Variants, ",")}
+		if g != w {
+			t.Errorf("problem %d: got %v, want %v", i, g, w)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package lint
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+	"honnef.co/go/tools/config"
+)
+
+func TestParseGoVersion(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+		ok   bool
+	}{
+		{"1.0", 0, true},
+		{"1.8", 8, true},
+		{"1.21", 21, true},
+		{"1.21.3", 21, true},
+		{"1.21rc1", 21, true},
+		{"1.22beta2", 22, true},
+		{"1.22alpha1", 22, true},
+		{"", 0, false},
+		{"1.", 0, false},
+		{"1.x", 0, false},
+		{"1.21foo", 0, false},
+		{"2.0", 0, false},
+		{"go1.21", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseGoVersion(tt.in)
+		if (err == nil) != tt.ok {
+			t.Errorf("ParseGoVersion(%q): got error %v, want ok %t", tt.in, err, tt.ok)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseGoVersion(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGoVersionPrecedence(t *testing.T) {
+	def := DefaultGoVersion()
+	tests := []struct {
+		name   string
+		flag   int
+		config string
+		gomod  string
+		want   int
+	}{
+		{"flag", 5, "1.6", "1.7", 5},
+		{"flag 1.0", 0, "1.6", "1.7", 0},
+		{"config", -1, "1.6", "1.7", 6},
+		{"go.mod", -1, "", "1.7", 7},
+		{"go.mod pre-release", -1, "", "1.21rc1", 21},
+		{"invalid go.mod", -1, "", "foo", def},
+		{"toolchain", -1, "", "", def},
+	}
+	for _, tt := range tests {
+		l := &Linter{GoVersion: tt.flag}
+		pkg := &packages.Package{}
+		if tt.gomod != "" {
+			pkg.Module = &packages.Module{GoVersion: tt.gomod}
+		}
+		if got := l.goVersion(pkg, config.Config{GoVersion: tt.config}); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -36,35 +36,35 @@ func (c *Checker) Init(prog *lint.Program) {}
 
 func (c *Checker) Checks() []lint.Check {
 	return []lint.Check{
-		{ID: "S1000", FilterGenerated: true, Fn: c.LintSingleCaseSelect, Doc: docS1000},
-		{ID: "S1001", FilterGenerated: true, Fn: c.LintLoopCopy, Doc: docS1001},
-		{ID: "S1002", FilterGenerated: true, Fn: c.LintIfBoolCmp, Doc: docS1002},
-		{ID: "S1003", FilterGenerated: true, Fn: c.LintStringsContains, Doc: docS1003},
-		{ID: "S1004", FilterGenerated: true, Fn: c.LintBytesCompare, Doc: docS1004},
-		{ID: "S1005", FilterGenerated: true, Fn: c.LintUnnecessaryBlank, Doc: docS1005},
-		{ID: "S1006", FilterGenerated: true, Fn: c.LintForTrue, Doc: docS1006},
-		{ID: "S1007", FilterGenerated: true, Fn: c.LintRegexpRaw, Doc: docS1007},
-		{ID: "S1008", FilterGenerated: true, Fn: c.LintIfReturn, Doc: docS1008},
-		{ID: "S1009", FilterGenerated: true, Fn: c.LintRedundantNilCheckWithLen, Doc: docS1009},
-		{ID: "S1010", FilterGenerated: true, Fn: c.LintSlicing, Doc: docS1010},
-		{ID: "S1011", FilterGenerated: true, Fn: c.LintLoopAppend, Doc: docS1011},
-		{ID: "S1012", FilterGenerated: true, Fn: c.LintTimeSince, Doc: docS1012},
-		{ID: "S1016", FilterGenerated: true, Fn: c.LintSimplerStructConversion, Doc: docS1016},
-		{ID: "S1017", FilterGenerated: true, Fn: c.LintTrim, Doc: docS1017},
-		{ID: "S1018", FilterGenerated: true, Fn: c.LintLoopSlide, Doc: docS1018},
-		{ID: "S1019", FilterGenerated: true, Fn: c.LintMakeLenCap, Doc: docS1019},
-		{ID: "S1020", FilterGenerated: true, Fn: c.LintAssertNotNil, Doc: docS1020},
-		{ID: "S1021", FilterGenerated: true, Fn: c.LintDeclareAssign, Doc: docS1021},
-		{ID: "S1023", FilterGenerated: true, Fn: c.LintRedundantBreak, Doc: docS1023},
-		{ID: "S1024", FilterGenerated: true, Fn: c.LintTimeUntil, Doc: docS1024},
-		{ID: "S1025", FilterGenerated: true, Fn: c.LintRedundantSprintf, Doc: docS1025},
-		{ID: "S1028", FilterGenerated: true, Fn: c.LintErrorsNewSprintf, Doc: docS1028},
-		{ID: "S1029", FilterGenerated: false, Fn: c.LintRangeStringRunes, Doc: docS1029},
-		{ID: "S1030", FilterGenerated: true, Fn: c.LintBytesBufferConversions, Doc: docS1030},
-		{ID: "S1031", FilterGenerated: true, Fn: c.LintNilCheckAroundRange, Doc: docS1031},
-		{ID: "S1032", FilterGenerated: true, Fn: c.LintSortHelpers, Doc: docS1032},
-		{ID: "S1033", FilterGenerated: true, Fn: c.LintGuardedDelete, Doc: ``},
-		{ID: "S1034", FilterGenerated: true, Fn: c.LintSimplifyTypeSwitch, Doc: ``},
+		{ID: "S1000", FilterGenerated: true, Fn: c.LintSingleCaseSelect, Doc: docS1000, Needs: lint.NeedsTypes},
+		{ID: "S1001", FilterGenerated: true, Fn: c.LintLoopCopy, Doc: docS1001, Needs: lint.NeedsTypes},
+		{ID: "S1002", FilterGenerated: true, Fn: c.LintIfBoolCmp, Doc: docS1002, Needs: lint.NeedsTypes},
+		{ID: "S1003", FilterGenerated: true, Fn: c.LintStringsContains, Doc: docS1003, Needs: lint.NeedsTypes},
+		{ID: "S1004", FilterGenerated: true, Fn: c.LintBytesCompare, Doc: docS1004, Needs: lint.NeedsTypes},
+		{ID: "S1005", FilterGenerated: true, Fn: c.LintUnnecessaryBlank, Doc: docS1005, Needs: lint.NeedsTypes},
+		{ID: "S1006", FilterGenerated: true, Fn: c.LintForTrue, Doc: docS1006, Needs: lint.NeedsTypes},
+		{ID: "S1007", FilterGenerated: true, Fn: c.LintRegexpRaw, Doc: docS1007, Needs: lint.NeedsTypes},
+		{ID: "S1008", FilterGenerated: true, Fn: c.LintIfReturn, Doc: docS1008, Needs: lint.NeedsTypes},
+		{ID: "S1009", FilterGenerated: true, Fn: c.LintRedundantNilCheckWithLen, Doc: docS1009, Needs: lint.NeedsTypes},
+		{ID: "S1010", FilterGenerated: true, Fn: c.LintSlicing, Doc: docS1010, Needs: lint.NeedsTypes},
+		{ID: "S1011", FilterGenerated: true, Fn: c.LintLoopAppend, Doc: docS1011, Needs: lint.NeedsTypes},
+		{ID: "S1012", FilterGenerated: true, Fn: c.LintTimeSince, Doc: docS1012, Needs: lint.NeedsTypes},
+		{ID: "S1016", FilterGenerated: true, Fn: c.LintSimplerStructConversion, Doc: docS1016, Needs: lint.NeedsTypes},
+		{ID: "S1017", FilterGenerated: true, Fn: c.LintTrim, Doc: docS1017, Needs: lint.NeedsTypes},
+		{ID: "S1018", FilterGenerated: true, Fn: c.LintLoopSlide, Doc: docS1018, Needs: lint.NeedsTypes},
+		{ID: "S1019", FilterGenerated: true, Fn: c.LintMakeLenCap, Doc: docS1019, Needs: lint.NeedsTypes},
+		{ID: "S1020", FilterGenerated: true, Fn: c.LintAssertNotNil, Doc: docS1020, Needs: lint.NeedsTypes},
+		{ID: "S1021", FilterGenerated: true, Fn: c.LintDeclareAssign, Doc: docS1021, Needs: lint.NeedsTypes},
+		{ID: "S1023", FilterGenerated: true, Fn: c.LintRedundantBreak, Doc: docS1023, Needs: lint.NeedsTypes},
+		{ID: "S1024", FilterGenerated: true, Fn: c.LintTimeUntil, Doc: docS1024, Needs: lint.NeedsTypes},
+		{ID: "S1025", FilterGenerated: true, Fn: c.LintRedundantSprintf, Doc: docS1025, Needs: lint.NeedsTypes},
+		{ID: "S1028", FilterGenerated: true, Fn: c.LintErrorsNewSprintf, Doc: docS1028, Needs: lint.NeedsTypes},
+		{ID: "S1029", FilterGenerated: false, Fn: c.LintRangeStringRunes, Doc: docS1029, Needs: lint.NeedsSSA},
+		{ID: "S1030", FilterGenerated: true, Fn: c.LintBytesBufferConversions, Doc: docS1030, Needs: lint.NeedsTypes},
+		{ID: "S1031", FilterGenerated: true, Fn: c.LintNilCheckAroundRange, Doc: docS1031, Needs: lint.NeedsTypes},
+		{ID: "S1032", FilterGenerated: true, Fn: c.LintSortHelpers, Doc: docS1032, Needs: lint.NeedsTypes},
+		{ID: "S1033", FilterGenerated: true, Fn: c.LintGuardedDelete, Doc: ``, Needs: lint.NeedsTypes},
+		{ID: "S1034", FilterGenerated: true, Fn: c.LintSimplifyTypeSwitch, Doc: ``, Needs: lint.NeedsTypes},
 	}
 }
 
@@ -209,7 +209,12 @@ func (c *Checker) LintIfBoolCmp(j *lint.Job) {
 		if (expr.Op == token.EQL && !val) || (expr.Op == token.NEQ && val) {
 			op = "!"
 		}
-		r := op + Render(j, other)
+		r := Render(j, other)
+		if _, ok := other.(*ast.BinaryExpr); ok && op == "!" {
+			// !a == b would negate a
+			r = "(" + r + ")"
+		}
+		r = op + r
 		l1 := len(r)
 		r = strings.TrimLeft(r, "!")
 		if (l1-len(r))%2 == 1 {
@@ -218,7 +223,8 @@ func (c *Checker) LintIfBoolCmp(j *lint.Job) {
 		if IsInTest(j, node) {
 			return
 		}
-		j.Errorf(expr, "should omit comparison to bool constant, can be simplified to %s", r)
+		p := j.Errorf(expr, "should omit comparison to bool constant, can be simplified to %s", r)
+		j.Edit(p, expr, r)
 	}
 	j.Pkg.Inspector.Preorder([]ast.Node{(*ast.BinaryExpr)(nil)}, fn)
 }
@@ -241,9 +247,11 @@ func (c *Checker) LintBytesBufferConversions(j *lint.Job) {
 
 		typ := j.Pkg.TypesInfo.TypeOf(call.Fun)
 		if typ == types.Universe.Lookup("string").Type() && IsCallToAST(j, call.Args[0], "(*bytes.Buffer).Bytes") {
-			j.Errorf(call, "should use %v.String() instead of %v", Render(j, sel.X), Render(j, call))
+			p := j.Errorf(call, "should use %v.String() instead of %v", Render(j, sel.X), Render(j, call))
+			j.Edit(p, call, Render(j, sel.X)+".String()")
 		} else if typ, ok := typ.(*types.Slice); ok && typ.Elem() == types.Universe.Lookup("byte").Type() && IsCallToAST(j, call.Args[0], "(*bytes.Buffer).String") {
-			j.Errorf(call, "should use %v.Bytes() instead of %v", Render(j, sel.X), Render(j, call))
+			p := j.Errorf(call, "should use %v.Bytes() instead of %v", Render(j, sel.X), Render(j, call))
+			j.Edit(p, call, Render(j, sel.X)+".Bytes()")
 		}
 
 	}
@@ -775,7 +783,8 @@ func (c *Checker) LintUnnecessaryBlank(j *lint.Job) {
 		}
 		cp := *assign
 		cp.Lhs = cp.Lhs[0:1]
-		j.Errorf(assign, "should write %s instead of %s", Render(j, &cp), Render(j, assign))
+		p := j.Errorf(assign, "should write %s instead of %s", Render(j, &cp), Render(j, assign))
+		j.Edit(p, assign, Render(j, &cp))
 	}
 
 	fn2 := func(node ast.Node) {
@@ -1258,14 +1267,16 @@ func (c *Checker) LintMakeLenCap(j *lint.Job) {
 				break
 			}
 			if IsZero(call.Args[Arg("make.size[0]")]) {
-				j.Errorf(call.Args[Arg("make.size[0]")], "should use make(%s) instead", Render(j, call.Args[Arg("make.t")]))
+				fix := fmt.Sprintf("make(%s)", Render(j, call.Args[Arg("make.t")]))
+				p := j.Errorf(call.Args[Arg("make.size[0]")], "should use %s instead", fix)
+				j.Edit(p, call, fix)
 			}
 		case 3:
 			// make(T, len, cap)
 			if Render(j, call.Args[Arg("make.size[0]")]) == Render(j, call.Args[Arg("make.size[1]")]) {
-				j.Errorf(call.Args[Arg("make.size[0]")],
-					"should use make(%s, %s) instead",
-					Render(j, call.Args[Arg("make.t")]), Render(j, call.Args[Arg("make.size[0]")]))
+				fix := fmt.Sprintf("make(%s, %s)", Render(j, call.Args[Arg("make.t")]), Render(j, call.Args[Arg("make.size[0]")]))
+				p := j.Errorf(call.Args[Arg("make.size[0]")], "should use %s instead", fix)
+				j.Edit(p, call, fix)
 			}
 		}
 	}
@@ -23,6 +23,16 @@ func fn() {
 	if (fn1() && fn2()) == false { // MATCH "simplified to !(fn1() && fn2())"
 	}
 
+	if fn1() == fn2() == false { // MATCH "simplified to !(fn1() == fn2())"
+	}
+	if fn1() == fn2() != true { // MATCH "simplified to !(fn1() == fn2())"
+	}
+	if fn1() == fn2() == true { // MATCH "simplified to fn1() == fn2()"
+	}
+	var a, b int
+	if a < b == false { // MATCH "simplified to !(a < b)"
+	}
+
 	var y bool
 	for y != true { // MATCH /simplified to !y/
 	}
@@ -0,0 +1,57 @@
+package staticcheck
+
+import (
+	"go/types"
+	"sync"
+
+	"honnef.co/go/tools/deprecated"
+	"honnef.co/go/tools/lint"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// moduleHistory lazily computes the deprecation history of a package
+// that belongs to a module in the module cache.
+type moduleHistory struct {
+	once sync.Once
+	pkg  *packages.Package
+	deps map[string]deprecated.ModuleDeprecation
+}
+
+func (h *moduleHistory) get() map[string]deprecated.ModuleDeprecation {
+	h.once.Do(func() {
+		m := h.pkg.Module
+		cache, ok := deprecated.ModuleCache(m.Dir, m.Path, m.Version)
+		if !ok {
+			return
+		}
+		// Errors only mean that we know less about the history.
+		h.deps, _ = deprecated.ModuleHistory(cache, m.Path, m.Version, h.pkg.PkgPath)
+	})
+	return h.deps
+}
+
+// findModuleHistories prepares the computation of deprecation
+// histories for all packages that belong to modules other than the
+// main module.
+func (c *Checker) findModuleHistories(prog *lint.Program) {
+	c.moduleHistories = map[*types.Package]*moduleHistory{}
+	for _, pkg := range prog.AllPackages {
+		m := pkg.Module
+		if m == nil || m.Main || m.Replace != nil || m.Version == "" || m.Dir == "" {
+			continue
+		}
+		c.moduleHistories[pkg.Types] = &moduleHistory{pkg: pkg}
+	}
+}
+
+// moduleDeprecation returns the deprecation history of the object obj,
+// named name, if it belongs to a module in the module cache.
+func (c *Checker) moduleDeprecation(obj types.Object, name string) (deprecated.ModuleDeprecation, *packages.Module, bool) {
+	h := c.moduleHistories[obj.Pkg()]
+	if h == nil {
+		return deprecated.ModuleDeprecation{}, nil, false
+	}
+	d, ok := h.get()[name]
+	return d, h.pkg.Module, ok
+}
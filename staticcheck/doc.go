@@ -188,6 +188,23 @@ Available since
 
 var docSA1019 = `Using a deprecated function, variable, constant or field
 
+Besides objects whose documentation contains a paragraph starting
+with "Deprecated: ", packages and objects can be deprecated in
+staticcheck.conf, optionally from a given date on:
+
+    [[deprecated]]
+    name = "example.com/legacy/log"
+    alternative = "use example.com/log instead"
+    since = "2019-06-01"
+
+Objects are named like in call rules, for example
+"(*example.com/db.Conn).Query".
+
+For dependencies, other versions of the module that are available in
+the local module cache are consulted as well. Problems mention the
+version that deprecated an object, and objects that are only
+deprecated in versions newer than the one in use are reported, too.
+
 Available since
     2017.1
 `
@@ -250,6 +267,52 @@ Available since
     Unreleased
 `
 
+var docSA1028 = `Argument violates a call rule from the configuration
+
+Call rules declared in staticcheck.conf constrain the arguments of
+functions, for example to require that an argument be a constant or
+a valid regular expression:
+
+    [[call_rules]]
+    function = "example.com/log.Fields"
+    argument = 0
+    rule = "constant"
+
+Arguments are counted from 0, not including the receiver. A rule for
+a variadic parameter applies to each of the variadic arguments.
+Supported rules are constant, not_nil, regexp, url, time_layout and
+host_port.
+An optional message replaces the default description of violations.
+
+Available since
+    Unreleased
+`
+
+var docSA1029 = `Use of a banned API
+
+Packages, functions, methods, types and variables can be banned in
+staticcheck.conf. Bans are inherited by subdirectories, and each ban
+may list directories in which the API is still allowed:
+
+    [[banned_apis]]
+    name = "io/ioutil"
+    message = "use the io and os packages instead"
+
+    [[banned_apis]]
+    name = "net/http.DefaultClient"
+    message = "use a client with timeouts"
+    allow = ["cmd/...", "internal/testutil"]
+
+Functions and methods are named like they are in call rules, for
+example "(*net/http.Client).Do". Any use of a banned function is
+reported, including passing it as a callback and taking a method
+value. Relative directories in allow lists are relative to the
+directory of the configuration file.
+
+Available since
+    Unreleased
+`
+
 var docSA2000 = `sync.WaitGroup.Add called inside the goroutine, leading to a race condition
 
 Available since
@@ -0,0 +1,201 @@
+package staticcheck
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/packages"
+	"honnef.co/go/tools/deprecated"
+	"honnef.co/go/tools/functions"
+	"honnef.co/go/tools/lint"
+	"honnef.co/go/tools/ssa"
+)
+
+// deprecationFact records that a package or object is deprecated.
+type deprecationFact struct {
+	// Alternative is the deprecation notice, which usually names an
+	// alternative.
+	Alternative string `json:"alternative"`
+}
+
+// purityFact records that a function is pure.
+type purityFact struct {
+	// Stub is set if the function is also a stub, whose return value
+	// is usually ignored on purpose.
+	Stub bool `json:"stub,omitempty"`
+}
+
+func (c *Checker) ComputeFacts(pkg *packages.Package, fns []*ssa.Function, facts *lint.Facts) {
+	exportDeprecations(pkg, facts)
+	exportPurity(pkg, fns, facts)
+}
+
+// exportDeprecations exports facts about pkg and its objects that
+// have been deprecated in their documentation.
+func exportDeprecations(pkg *packages.Package, facts *lint.Facts) {
+	var names []*ast.Ident
+
+	extractDeprecatedMessage := func(docs []*ast.CommentGroup) string {
+		for _, doc := range docs {
+			if doc == nil {
+				continue
+			}
+			if alt, ok := deprecated.Paragraph(doc.Text()); ok {
+				return alt
+			}
+		}
+		return ""
+	}
+	doDocs := func(names []*ast.Ident, docs []*ast.CommentGroup) {
+		alt := extractDeprecatedMessage(docs)
+		if alt == "" {
+			return
+		}
+
+		for _, name := range names {
+			obj := pkg.TypesInfo.ObjectOf(name)
+			facts.ExportObjectFact(obj, &deprecationFact{alt})
+		}
+	}
+
+	var docs []*ast.CommentGroup
+	for _, f := range pkg.Syntax {
+		docs = append(docs, f.Doc)
+	}
+	if alt := extractDeprecatedMessage(docs); alt != "" {
+		// Don't mark package syscall as deprecated, even though
+		// it is. A lot of people still use it for simple
+		// constants like SIGKILL, and I am not comfortable
+		// telling them to use x/sys for that.
+		if pkg.PkgPath != "syscall" {
+			facts.ExportPackageFact(pkg.Types, &deprecationFact{alt})
+		}
+	}
+
+	docs = docs[:0]
+	for _, f := range pkg.Syntax {
+		var fn func(node ast.Node) bool
+		fn = func(node ast.Node) bool {
+			if node == nil {
+				return true
+			}
+			var (
+				ret bool
+				// typ is the type of a variable, which may contain
+				// struct types with deprecated fields.
+				typ ast.Expr
+			)
+			switch node := node.(type) {
+			case *ast.GenDecl:
+				switch node.Tok {
+				case token.TYPE, token.CONST, token.VAR:
+					docs = append(docs, node.Doc)
+					return true
+				default:
+					return false
+				}
+			case *ast.FuncDecl:
+				docs = append(docs, node.Doc)
+				names = []*ast.Ident{node.Name}
+				ret = false
+			case *ast.TypeSpec:
+				docs = append(docs, node.Doc)
+				names = []*ast.Ident{node.Name}
+				ret = true
+			case *ast.ValueSpec:
+				docs = append(docs, node.Doc)
+				names = node.Names
+				typ = node.Type
+				ret = false
+			case *ast.File:
+				return true
+			case *ast.StarExpr, *ast.ArrayType, *ast.MapType, *ast.ChanType:
+				// may contain struct types
+				return true
+			case *ast.StructType:
+				for _, field := range node.Fields.List {
+					doDocs(field.Names, []*ast.CommentGroup{field.Doc})
+					// nested struct types
+					ast.Inspect(field.Type, fn)
+				}
+				return false
+			case *ast.InterfaceType:
+				for _, field := range node.Methods.List {
+					doDocs(field.Names, []*ast.CommentGroup{field.Doc})
+				}
+				return false
+			default:
+				return false
+			}
+			if len(names) == 0 || len(docs) == 0 {
+				return ret
+			}
+			doDocs(names, docs)
+
+			docs = docs[:0]
+			names = nil
+			if typ != nil {
+				ast.Inspect(typ, fn)
+			}
+			return ret
+		}
+		ast.Inspect(f, fn)
+	}
+}
+
+// exportPurity exports facts about the functions of pkg that are
+// pure. Functions of other packages are pure if they have a purity
+// fact; calls within the package are followed, and recursive
+// functions aren't pure.
+func exportPurity(pkg *packages.Package, fns []*ssa.Function, facts *lint.Facts) {
+	const (
+		unknown = iota
+		inProgress
+		pure
+		impure
+	)
+	state := map[*ssa.Function]int{}
+	var isPure func(fn *ssa.Function) bool
+	calleePure := func(callee *ssa.Function) bool {
+		if callee.Pkg != nil && callee.Pkg.Pkg != pkg.Types {
+			var fact purityFact
+			return facts.ImportObjectFact(callee.Object(), &fact) || functions.Known(callee).Pure
+		}
+		return isPure(callee)
+	}
+	isPure = func(fn *ssa.Function) bool {
+		switch state[fn] {
+		case inProgress, impure:
+			return false
+		case pure:
+			return true
+		}
+		state[fn] = inProgress
+		ok := functions.Known(fn).Pure || functions.IsPureWith(fn, calleePure)
+		if ok {
+			state[fn] = pure
+		} else {
+			state[fn] = impure
+		}
+		return ok
+	}
+
+	for _, fn := range fns {
+		if fn.Object() == nil || !isPure(fn) {
+			continue
+		}
+		stub := functions.Known(fn).Stub || functions.IsStub(fn)
+		facts.ExportObjectFact(fn.Object(), &purityFact{Stub: stub})
+	}
+}
+
+// isDeprecated returns the deprecation notice of obj, if it is
+// deprecated.
+func isDeprecated(facts *lint.Facts, obj types.Object) (bool, string) {
+	var fact deprecationFact
+	if !facts.ImportObjectFact(obj, &fact) {
+		return false, ""
+	}
+	return true, fact.Alternative
+}
@@ -9,6 +9,7 @@ import (
 	"go/types"
 	htmltemplate "html/template"
 	"net/http"
+	"path/filepath"
 	"reflect"
 	"regexp"
 	"regexp/syntax"
@@ -17,9 +18,11 @@ import (
 	"strings"
 	"sync"
 	texttemplate "text/template"
+	"time"
 	"unicode"
 
 	. "honnef.co/go/tools/arg"
+	"honnef.co/go/tools/config"
 	"honnef.co/go/tools/deprecated"
 	"honnef.co/go/tools/functions"
 	"honnef.co/go/tools/internal/sharedcheck"
@@ -31,7 +34,6 @@ import (
 	"honnef.co/go/tools/staticcheck/vrp"
 
 	"golang.org/x/tools/go/ast/astutil"
-	"golang.org/x/tools/go/packages"
 )
 
 func validRegexp(call *Call) {
@@ -734,8 +736,9 @@ func fieldPath(start types.Type, indices []int) string {
 type Checker struct {
 	CheckGenerated bool
 	funcDescs      *functions.Descriptions
-	deprecatedPkgs map[*types.Package]string
-	deprecatedObjs map[types.Object]string
+	// moduleHistories maps packages of dependencies to their
+	// deprecation histories.
+	moduleHistories map[*types.Package]*moduleHistory
 }
 
 func NewChecker() *Checker {
@@ -748,82 +751,84 @@ func (*Checker) Prefix() string { return "SA" }
 func (c *Checker) Checks() []lint.Check {
 	return []lint.Check{
 		{ID: "SA1000", FilterGenerated: false, Fn: c.callChecker(checkRegexpRules), Doc: docSA1000},
-		{ID: "SA1001", FilterGenerated: false, Fn: c.CheckTemplate, Doc: docSA1001},
+		{ID: "SA1001", FilterGenerated: false, Fn: c.CheckTemplate, Doc: docSA1001, Needs: lint.NeedsTypes},
 		{ID: "SA1002", FilterGenerated: false, Fn: c.callChecker(checkTimeParseRules), Doc: docSA1002},
 		{ID: "SA1003", FilterGenerated: false, Fn: c.callChecker(checkEncodingBinaryRules), Doc: docSA1003},
-		{ID: "SA1004", FilterGenerated: false, Fn: c.CheckTimeSleepConstant, Doc: docSA1004},
-		{ID: "SA1005", FilterGenerated: false, Fn: c.CheckExec, Doc: docSA1005},
-		{ID: "SA1006", FilterGenerated: false, Fn: c.CheckUnsafePrintf, Doc: docSA1006},
+		{ID: "SA1004", FilterGenerated: false, Fn: c.CheckTimeSleepConstant, Doc: docSA1004, Needs: lint.NeedsTypes},
+		{ID: "SA1005", FilterGenerated: false, Fn: c.CheckExec, Doc: docSA1005, Needs: lint.NeedsTypes},
+		{ID: "SA1006", FilterGenerated: false, Fn: c.CheckUnsafePrintf, Doc: docSA1006, Needs: lint.NeedsTypes},
 		{ID: "SA1007", FilterGenerated: false, Fn: c.callChecker(checkURLsRules), Doc: docSA1007},
-		{ID: "SA1008", FilterGenerated: false, Fn: c.CheckCanonicalHeaderKey, Doc: docSA1008},
+		{ID: "SA1008", FilterGenerated: false, Fn: c.CheckCanonicalHeaderKey, Doc: docSA1008, Needs: lint.NeedsTypes},
 		{ID: "SA1010", FilterGenerated: false, Fn: c.callChecker(checkRegexpFindAllRules), Doc: docSA1010},
 		{ID: "SA1011", FilterGenerated: false, Fn: c.callChecker(checkUTF8CutsetRules), Doc: docSA1011},
-		{ID: "SA1012", FilterGenerated: false, Fn: c.CheckNilContext, Doc: docSA1012},
-		{ID: "SA1013", FilterGenerated: false, Fn: c.CheckSeeker, Doc: docSA1013},
+		{ID: "SA1012", FilterGenerated: false, Fn: c.CheckNilContext, Doc: docSA1012, Needs: lint.NeedsTypes},
+		{ID: "SA1013", FilterGenerated: false, Fn: c.CheckSeeker, Doc: docSA1013, Needs: lint.NeedsTypes},
 		{ID: "SA1014", FilterGenerated: false, Fn: c.callChecker(checkUnmarshalPointerRules), Doc: docSA1014},
 		{ID: "SA1015", FilterGenerated: false, Fn: c.CheckLeakyTimeTick, Doc: docSA1015},
-		{ID: "SA1016", FilterGenerated: false, Fn: c.CheckUntrappableSignal, Doc: docSA1016},
+		{ID: "SA1016", FilterGenerated: false, Fn: c.CheckUntrappableSignal, Doc: docSA1016, Needs: lint.NeedsTypes},
 		{ID: "SA1017", FilterGenerated: false, Fn: c.callChecker(checkUnbufferedSignalChanRules), Doc: docSA1017},
 		{ID: "SA1018", FilterGenerated: false, Fn: c.callChecker(checkStringsReplaceZeroRules), Doc: docSA1018},
 		{ID: "SA1019", FilterGenerated: false, Fn: c.CheckDeprecated, Doc: docSA1019},
 		{ID: "SA1020", FilterGenerated: false, Fn: c.callChecker(checkListenAddressRules), Doc: docSA1020},
 		{ID: "SA1021", FilterGenerated: false, Fn: c.callChecker(checkBytesEqualIPRules), Doc: docSA1021},
-		{ID: "SA1023", FilterGenerated: false, Fn: c.CheckWriterBufferModified, Doc: docSA1023},
+		{ID: "SA1023", FilterGenerated: false, Fn: c.CheckWriterBufferModified, Doc: docSA1023, Needs: lint.NeedsSSA},
 		{ID: "SA1024", FilterGenerated: false, Fn: c.callChecker(checkUniqueCutsetRules), Doc: docSA1024},
-		{ID: "SA1025", FilterGenerated: false, Fn: c.CheckTimerResetReturnValue, Doc: docSA1025},
+		{ID: "SA1025", FilterGenerated: false, Fn: c.CheckTimerResetReturnValue, Doc: docSA1025, Needs: lint.NeedsSSA},
 		{ID: "SA1026", FilterGenerated: false, Fn: c.callChecker(checkUnsupportedMarshal), Doc: docSA1026},
 		{ID: "SA1027", FilterGenerated: false, Fn: c.callChecker(checkAtomicAlignment), Doc: docSA1027},
+		{ID: "SA1028", FilterGenerated: false, Fn: c.CheckCallRules, Doc: docSA1028},
+		{ID: "SA1029", FilterGenerated: true, Fn: c.CheckBannedAPIs, Doc: docSA1029, Needs: lint.NeedsTypes},
 
-		{ID: "SA2000", FilterGenerated: false, Fn: c.CheckWaitgroupAdd, Doc: docSA2000},
-		{ID: "SA2001", FilterGenerated: false, Fn: c.CheckEmptyCriticalSection, Doc: docSA2001},
-		{ID: "SA2002", FilterGenerated: false, Fn: c.CheckConcurrentTesting, Doc: docSA2002},
-		{ID: "SA2003", FilterGenerated: false, Fn: c.CheckDeferLock, Doc: docSA2003},
+		{ID: "SA2000", FilterGenerated: false, Fn: c.CheckWaitgroupAdd, Doc: docSA2000, Needs: lint.NeedsTypes},
+		{ID: "SA2001", FilterGenerated: false, Fn: c.CheckEmptyCriticalSection, Doc: docSA2001, Needs: lint.NeedsTypes},
+		{ID: "SA2002", FilterGenerated: false, Fn: c.CheckConcurrentTesting, Doc: docSA2002, Needs: lint.NeedsSSA},
+		{ID: "SA2003", FilterGenerated: false, Fn: c.CheckDeferLock, Doc: docSA2003, Needs: lint.NeedsSSA},
 
-		{ID: "SA3000", FilterGenerated: false, Fn: c.CheckTestMainExit, Doc: docSA3000},
-		{ID: "SA3001", FilterGenerated: false, Fn: c.CheckBenchmarkN, Doc: docSA3001},
+		{ID: "SA3000", FilterGenerated: false, Fn: c.CheckTestMainExit, Doc: docSA3000, Needs: lint.NeedsTypes},
+		{ID: "SA3001", FilterGenerated: false, Fn: c.CheckBenchmarkN, Doc: docSA3001, Needs: lint.NeedsTypes},
 
-		{ID: "SA4000", FilterGenerated: false, Fn: c.CheckLhsRhsIdentical, Doc: docSA4000},
-		{ID: "SA4001", FilterGenerated: false, Fn: c.CheckIneffectiveCopy, Doc: docSA4001},
+		{ID: "SA4000", FilterGenerated: false, Fn: c.CheckLhsRhsIdentical, Doc: docSA4000, Needs: lint.NeedsTypes},
+		{ID: "SA4001", FilterGenerated: false, Fn: c.CheckIneffectiveCopy, Doc: docSA4001, Needs: lint.NeedsTypes},
 		{ID: "SA4002", FilterGenerated: false, Fn: c.CheckDiffSizeComparison, Doc: docSA4002},
-		{ID: "SA4003", FilterGenerated: false, Fn: c.CheckExtremeComparison, Doc: docSA4003},
-		{ID: "SA4004", FilterGenerated: false, Fn: c.CheckIneffectiveLoop, Doc: docSA4004},
-		{ID: "SA4006", FilterGenerated: false, Fn: c.CheckUnreadVariableValues, Doc: docSA4006},
+		{ID: "SA4003", FilterGenerated: false, Fn: c.CheckExtremeComparison, Doc: docSA4003, Needs: lint.NeedsTypes},
+		{ID: "SA4004", FilterGenerated: false, Fn: c.CheckIneffectiveLoop, Doc: docSA4004, Needs: lint.NeedsTypes},
+		{ID: "SA4006", FilterGenerated: false, Fn: c.CheckUnreadVariableValues, Doc: docSA4006, Needs: lint.NeedsSSA},
 		{ID: "SA4008", FilterGenerated: false, Fn: c.CheckLoopCondition, Doc: docSA4008},
-		{ID: "SA4009", FilterGenerated: false, Fn: c.CheckArgOverwritten, Doc: docSA4009},
-		{ID: "SA4010", FilterGenerated: false, Fn: c.CheckIneffectiveAppend, Doc: docSA4010},
-		{ID: "SA4011", FilterGenerated: false, Fn: c.CheckScopedBreak, Doc: docSA4011},
-		{ID: "SA4012", FilterGenerated: false, Fn: c.CheckNaNComparison, Doc: docSA4012},
-		{ID: "SA4013", FilterGenerated: false, Fn: c.CheckDoubleNegation, Doc: docSA4013},
-		{ID: "SA4014", FilterGenerated: false, Fn: c.CheckRepeatedIfElse, Doc: docSA4014},
+		{ID: "SA4009", FilterGenerated: false, Fn: c.CheckArgOverwritten, Doc: docSA4009, Needs: lint.NeedsSSA},
+		{ID: "SA4010", FilterGenerated: false, Fn: c.CheckIneffectiveAppend, Doc: docSA4010, Needs: lint.NeedsSSA},
+		{ID: "SA4011", FilterGenerated: false, Fn: c.CheckScopedBreak, Doc: docSA4011, Needs: lint.NeedsTypes},
+		{ID: "SA4012", FilterGenerated: false, Fn: c.CheckNaNComparison, Doc: docSA4012, Needs: lint.NeedsSSA},
+		{ID: "SA4013", FilterGenerated: false, Fn: c.CheckDoubleNegation, Doc: docSA4013, Needs: lint.NeedsTypes},
+		{ID: "SA4014", FilterGenerated: false, Fn: c.CheckRepeatedIfElse, Doc: docSA4014, Needs: lint.NeedsTypes},
 		{ID: "SA4015", FilterGenerated: false, Fn: c.callChecker(checkMathIntRules), Doc: docSA4015},
-		{ID: "SA4016", FilterGenerated: false, Fn: c.CheckSillyBitwiseOps, Doc: docSA4016},
-		{ID: "SA4017", FilterGenerated: false, Fn: c.CheckPureFunctions, Doc: docSA4017},
-		{ID: "SA4018", FilterGenerated: true, Fn: c.CheckSelfAssignment, Doc: docSA4018},
-		{ID: "SA4019", FilterGenerated: true, Fn: c.CheckDuplicateBuildConstraints, Doc: docSA4019},
-		{ID: "SA4020", FilterGenerated: false, Fn: c.CheckUnreachableTypeCases, Doc: docSA4020},
-		{ID: "SA4021", FilterGenerated: true, Fn: c.CheckSingleArgAppend, Doc: docSA4021},
-
-		{ID: "SA5000", FilterGenerated: false, Fn: c.CheckNilMaps, Doc: docSA5000},
-		{ID: "SA5001", FilterGenerated: false, Fn: c.CheckEarlyDefer, Doc: docSA5001},
-		{ID: "SA5002", FilterGenerated: false, Fn: c.CheckInfiniteEmptyLoop, Doc: docSA5002},
-		{ID: "SA5003", FilterGenerated: false, Fn: c.CheckDeferInInfiniteLoop, Doc: docSA5003},
-		{ID: "SA5004", FilterGenerated: false, Fn: c.CheckLoopEmptyDefault, Doc: docSA5004},
+		{ID: "SA4016", FilterGenerated: false, Fn: c.CheckSillyBitwiseOps, Doc: docSA4016, Needs: lint.NeedsSSA},
+		{ID: "SA4017", FilterGenerated: false, Fn: c.CheckPureFunctions, Doc: docSA4017, Needs: lint.NeedsSSA},
+		{ID: "SA4018", FilterGenerated: true, Fn: c.CheckSelfAssignment, Doc: docSA4018, Needs: lint.NeedsTypes},
+		{ID: "SA4019", FilterGenerated: true, Fn: c.CheckDuplicateBuildConstraints, Doc: docSA4019, Needs: lint.NeedsTypes},
+		{ID: "SA4020", FilterGenerated: false, Fn: c.CheckUnreachableTypeCases, Doc: docSA4020, Needs: lint.NeedsTypes},
+		{ID: "SA4021", FilterGenerated: true, Fn: c.CheckSingleArgAppend, Doc: docSA4021, Needs: lint.NeedsTypes},
+
+		{ID: "SA5000", FilterGenerated: false, Fn: c.CheckNilMaps, Doc: docSA5000, Needs: lint.NeedsSSA},
+		{ID: "SA5001", FilterGenerated: false, Fn: c.CheckEarlyDefer, Doc: docSA5001, Needs: lint.NeedsTypes},
+		{ID: "SA5002", FilterGenerated: false, Fn: c.CheckInfiniteEmptyLoop, Doc: docSA5002, Needs: lint.NeedsTypes},
+		{ID: "SA5003", FilterGenerated: false, Fn: c.CheckDeferInInfiniteLoop, Doc: docSA5003, Needs: lint.NeedsTypes},
+		{ID: "SA5004", FilterGenerated: false, Fn: c.CheckLoopEmptyDefault, Doc: docSA5004, Needs: lint.NeedsTypes},
 		{ID: "SA5005", FilterGenerated: false, Fn: c.CheckCyclicFinalizer, Doc: docSA5005},
 		{ID: "SA5007", FilterGenerated: false, Fn: c.CheckInfiniteRecursion, Doc: docSA5007},
-		{ID: "SA5008", FilterGenerated: false, Fn: c.CheckStructTags, Doc: ``},
+		{ID: "SA5008", FilterGenerated: false, Fn: c.CheckStructTags, Doc: ``, Needs: lint.NeedsTypes},
 		{ID: "SA5009", FilterGenerated: false, Fn: c.callChecker(checkPrintfRules), Doc: ``},
 
 		{ID: "SA6000", FilterGenerated: false, Fn: c.callChecker(checkRegexpMatchLoopRules), Doc: docSA6000},
-		{ID: "SA6001", FilterGenerated: false, Fn: c.CheckMapBytesKey, Doc: docSA6001},
+		{ID: "SA6001", FilterGenerated: false, Fn: c.CheckMapBytesKey, Doc: docSA6001, Needs: lint.NeedsSSA},
 		{ID: "SA6002", FilterGenerated: false, Fn: c.callChecker(checkSyncPoolValueRules), Doc: docSA6002},
-		{ID: "SA6003", FilterGenerated: false, Fn: c.CheckRangeStringRunes, Doc: docSA6003},
+		{ID: "SA6003", FilterGenerated: false, Fn: c.CheckRangeStringRunes, Doc: docSA6003, Needs: lint.NeedsSSA},
 		// {ID: "SA6004", FilterGenerated: false, Fn: c.CheckSillyRegexp, Doc: docSA6004},
-		{ID: "SA6005", FilterGenerated: false, Fn: c.CheckToLowerToUpperComparison, Doc: docSA6005},
+		{ID: "SA6005", FilterGenerated: false, Fn: c.CheckToLowerToUpperComparison, Doc: docSA6005, Needs: lint.NeedsTypes},
 
-		{ID: "SA9001", FilterGenerated: false, Fn: c.CheckDubiousDeferInChannelRangeLoop, Doc: docSA9001},
-		{ID: "SA9002", FilterGenerated: false, Fn: c.CheckNonOctalFileMode, Doc: docSA9002},
-		{ID: "SA9003", FilterGenerated: false, Fn: c.CheckEmptyBranch, Doc: docSA9003},
-		{ID: "SA9004", FilterGenerated: false, Fn: c.CheckMissingEnumTypesInDeclaration, Doc: docSA9004},
+		{ID: "SA9001", FilterGenerated: false, Fn: c.CheckDubiousDeferInChannelRangeLoop, Doc: docSA9001, Needs: lint.NeedsTypes},
+		{ID: "SA9002", FilterGenerated: false, Fn: c.CheckNonOctalFileMode, Doc: docSA9002, Needs: lint.NeedsTypes},
+		{ID: "SA9003", FilterGenerated: false, Fn: c.CheckEmptyBranch, Doc: docSA9003, Needs: lint.NeedsSSA},
+		{ID: "SA9004", FilterGenerated: false, Fn: c.CheckMissingEnumTypesInDeclaration, Doc: docSA9004, Needs: lint.NeedsTypes},
 		// Filtering generated code because it may include empty structs generated from data models.
 		{ID: "SA9005", FilterGenerated: true, Fn: c.callChecker(checkNoopMarshal), Doc: docSA9005},
 	}
@@ -832,133 +837,27 @@ func (c *Checker) Checks() []lint.Check {
 	// "SA4007": c.CheckPredeterminedBooleanExprs,
 }
 
-func (c *Checker) findDeprecated(prog *lint.Program) {
-	var names []*ast.Ident
-
-	extractDeprecatedMessage := func(docs []*ast.CommentGroup) string {
-		for _, doc := range docs {
-			if doc == nil {
-				continue
-			}
-			parts := strings.Split(doc.Text(), "\n\n")
-			last := parts[len(parts)-1]
-			if !strings.HasPrefix(last, "Deprecated: ") {
-				continue
-			}
-			alt := last[len("Deprecated: "):]
-			alt = strings.Replace(alt, "\n", " ", -1)
-			return alt
-		}
-		return ""
-	}
-	doDocs := func(pkg *packages.Package, names []*ast.Ident, docs []*ast.CommentGroup) {
-		alt := extractDeprecatedMessage(docs)
-		if alt == "" {
-			return
-		}
-
-		for _, name := range names {
-			obj := pkg.TypesInfo.ObjectOf(name)
-			c.deprecatedObjs[obj] = alt
-		}
-	}
-
-	for _, pkg := range prog.AllPackages {
-		var docs []*ast.CommentGroup
-		for _, f := range pkg.Syntax {
-			docs = append(docs, f.Doc)
-		}
-		if alt := extractDeprecatedMessage(docs); alt != "" {
-			// Don't mark package syscall as deprecated, even though
-			// it is. A lot of people still use it for simple
-			// constants like SIGKILL, and I am not comfortable
-			// telling them to use x/sys for that.
-			if pkg.PkgPath != "syscall" {
-				c.deprecatedPkgs[pkg.Types] = alt
-			}
-		}
-
-		docs = docs[:0]
-		for _, f := range pkg.Syntax {
-			fn := func(node ast.Node) bool {
-				if node == nil {
-					return true
-				}
-				var ret bool
-				switch node := node.(type) {
-				case *ast.GenDecl:
-					switch node.Tok {
-					case token.TYPE, token.CONST, token.VAR:
-						docs = append(docs, node.Doc)
-						return true
-					default:
-						return false
-					}
-				case *ast.FuncDecl:
-					docs = append(docs, node.Doc)
-					names = []*ast.Ident{node.Name}
-					ret = false
-				case *ast.TypeSpec:
-					docs = append(docs, node.Doc)
-					names = []*ast.Ident{node.Name}
-					ret = true
-				case *ast.ValueSpec:
-					docs = append(docs, node.Doc)
-					names = node.Names
-					ret = false
-				case *ast.File:
-					return true
-				case *ast.StructType:
-					for _, field := range node.Fields.List {
-						doDocs(pkg, field.Names, []*ast.CommentGroup{field.Doc})
-					}
-					return false
-				case *ast.InterfaceType:
-					for _, field := range node.Methods.List {
-						doDocs(pkg, field.Names, []*ast.CommentGroup{field.Doc})
-					}
-					return false
-				default:
-					return false
-				}
-				if len(names) == 0 || len(docs) == 0 {
-					return ret
-				}
-				doDocs(pkg, names, docs)
-
-				docs = docs[:0]
-				names = nil
-				return ret
-			}
-			ast.Inspect(f, fn)
-		}
-	}
-}
-
 func (c *Checker) Init(prog *lint.Program) {
 	wg := &sync.WaitGroup{}
 	wg.Add(2)
 	go func() {
 		c.funcDescs = functions.NewDescriptions(prog.SSA)
-		for _, fn := range prog.AllFunctions {
-			if fn.Blocks != nil {
-				applyStdlibKnowledge(fn)
-				ssa.OptimizeBlocks(fn)
-			}
-		}
 		wg.Done()
 	}()
 
 	go func() {
-		c.deprecatedPkgs = map[*types.Package]string{}
-		c.deprecatedObjs = map[types.Object]string{}
-		c.findDeprecated(prog)
+		c.findModuleHistories(prog)
 		wg.Done()
 	}()
 
 	wg.Wait()
 }
 
+func (c *Checker) PrepareFunction(fn *ssa.Function) {
+	applyStdlibKnowledge(fn)
+	ssa.OptimizeBlocks(fn)
+}
+
 func (c *Checker) isInLoop(b *ssa.BasicBlock) bool {
 	sets := c.funcDescs.Get(b.Parent()).Loops
 	for _, set := range sets {
@@ -1662,7 +1561,12 @@ func (c *Checker) CheckDiffSizeComparison(j *lint.Job) {
 				if !ok1 && !ok2 {
 					continue
 				}
-				r := c.funcDescs.Get(ssafn).Ranges
+				desc, err := c.funcDescs.GetContext(j.Context(), ssafn)
+				if err != nil {
+					j.Abandon()
+					return
+				}
+				r := desc.Ranges
 				r1, ok1 := r.Get(binop.X).(vrp.StringInterval)
 				r2, ok2 := r.Get(binop.Y).(vrp.StringInterval)
 				if !ok1 || !ok2 {
@@ -2466,8 +2370,13 @@ func (c *Checker) CheckSliceOutOfBounds(j *lint.Job) {
 				if _, ok := ia.X.Type().Underlying().(*types.Slice); !ok {
 					continue
 				}
-				sr, ok1 := c.funcDescs.Get(ssafn).Ranges[ia.X].(vrp.SliceInterval)
-				idxr, ok2 := c.funcDescs.Get(ssafn).Ranges[ia.Index].(vrp.IntInterval)
+				desc, err := c.funcDescs.GetContext(j.Context(), ssafn)
+				if err != nil {
+					j.Abandon()
+					return
+				}
+				sr, ok1 := desc.Ranges[ia.X].(vrp.SliceInterval)
+				idxr, ok2 := desc.Ranges[ia.Index].(vrp.IntInterval)
 				if !ok1 || !ok2 || !sr.IsKnown() || !idxr.IsKnown() || sr.Length.Empty() || idxr.Empty() {
 					continue
 				}
@@ -2613,7 +2522,12 @@ func (c *Checker) CheckLeakyTimeTick(j *lint.Job) {
 				if !ok || !IsCallTo(call.Common(), "time.Tick") {
 					continue
 				}
-				if c.funcDescs.Get(call.Parent()).Infinite {
+				desc, err := c.funcDescs.GetContext(j.Context(), call.Parent())
+				if err != nil {
+					j.Abandon()
+					return
+				}
+				if desc.Infinite {
 					continue
 				}
 				j.Errorf(call, "using time.Tick leaks the underlying ticker, consider using it only in endless functions, tests and the main package, and use time.NewTicker here")
@@ -2805,7 +2719,17 @@ fnLoop:
 				if callee == nil {
 					continue
 				}
-				if c.funcDescs.Get(callee).Pure && !c.funcDescs.Get(callee).Stub {
+				var fact purityFact
+				if !j.Facts.ImportObjectFact(callee.Object(), &fact) {
+					// The callee's package may have been loaded
+					// without facts.
+					known := functions.Known(callee)
+					if !known.Pure {
+						continue
+					}
+					fact.Stub = known.Stub || functions.IsStub(callee)
+				}
+				if !fact.Stub {
 					j.Errorf(ins, "%s is a pure function but its return value is ignored", callee.Name())
 					continue
 				}
@@ -2814,16 +2738,43 @@ fnLoop:
 	}
 }
 
-func (c *Checker) isDeprecated(j *lint.Job, ident *ast.Ident) (bool, string) {
-	obj := j.Pkg.TypesInfo.ObjectOf(ident)
-	if obj.Pkg() == nil {
-		return false, ""
+// configDeprecations returns the deprecations declared in the
+// package's configuration that are in effect, keyed by name.
+func configDeprecations(j *lint.Job) map[string]config.Deprecation {
+	now := time.Now()
+	out := map[string]config.Deprecation{}
+	for _, d := range j.Pkg.Config.Deprecated {
+		if d.Effective(now) {
+			out[d.Name] = d
+		} else {
+			delete(out, d.Name)
+		}
+	}
+	return out
+}
+
+// deprecationName returns the name of the object selected by sel, in
+// the format used by deprecations in the configuration.
+func deprecationName(j *lint.Job, sel *ast.SelectorExpr, obj types.Object) string {
+	if fn, ok := obj.(*types.Func); ok {
+		return lint.FuncName(fn)
+	}
+	if s, ok := j.Pkg.TypesInfo.Selections[sel]; ok {
+		// a field
+		return fmt.Sprintf("(%s).%s", Dereference(s.Recv()), obj.Name())
 	}
-	alt := c.deprecatedObjs[obj]
-	return alt != "", alt
+	return obj.Pkg().Path() + "." + obj.Name()
 }
 
 func (c *Checker) CheckDeprecated(j *lint.Job) {
+	cfgDeps := configDeprecations(j)
+	deprecationMsg := func(name string, d config.Deprecation) string {
+		if d.Alternative == "" {
+			return name + " is deprecated"
+		}
+		return name + " is deprecated: " + d.Alternative
+	}
+
 	// Selectors can appear outside of function literals, e.g. when
 	// declaring package level variables.
 
@@ -2855,7 +2806,18 @@ func (c *Checker) CheckDeprecated(j *lint.Job) {
 			// Don't flag stuff in our own package
 			return true
 		}
-		if ok, alt := c.isDeprecated(j, sel.Sel); ok {
+		if ssafn != nil {
+			if ok, _ := isDeprecated(j.Facts, ssafn.Object()); ok {
+				// functions that are deprecated may use deprecated
+				// symbols
+				return true
+			}
+		}
+		if d, ok := cfgDeps[deprecationName(j, sel, obj)]; ok {
+			j.Errorf(sel, "%s", deprecationMsg(Render(j, sel), d))
+			return true
+		}
+		if ok, alt := isDeprecated(j.Facts, obj); ok {
 			// Look for the first available alternative, not the first
 			// version something was deprecated in. If a function was
 			// deprecated in Go 1.6, an alternative has been available
@@ -2867,16 +2829,17 @@ func (c *Checker) CheckDeprecated(j *lint.Job) {
 				return true
 			}
 
-			if ssafn != nil {
-				if _, ok := c.deprecatedObjs[ssafn.Object()]; ok {
-					// functions that are deprecated may use deprecated
-					// symbols
-					return true
-				}
+			if d, m, ok := c.moduleDeprecation(obj, deprecationName(j, sel, obj)); ok && d.Since != "" {
+				j.Errorf(sel, "%s has been deprecated since %s %s: %s", Render(j, sel), m.Path, d.Since, alt)
+				return true
 			}
 			j.Errorf(sel, "%s is deprecated: %s", Render(j, sel), alt)
 			return true
 		}
+		if d, m, ok := c.moduleDeprecation(obj, deprecationName(j, sel, obj)); ok && d.Upstream != "" {
+			j.Errorf(sel, "%s is deprecated in %s %s, which is newer than the version in use (%s): %s",
+				Render(j, sel), m.Path, d.Upstream, m.Version, d.Alternative)
+		}
 		return true
 	}
 	for _, f := range j.Pkg.Syntax {
@@ -2885,8 +2848,10 @@ func (c *Checker) CheckDeprecated(j *lint.Job) {
 				p := node.Path.Value
 				path := p[1 : len(p)-1]
 				imp := j.Pkg.Imports[path]
-				if alt := c.deprecatedPkgs[imp.Types]; alt != "" {
-					j.Errorf(node, "Package %s is deprecated: %s", path, alt)
+				if d, ok := cfgDeps[path]; ok {
+					j.Errorf(node, "%s", deprecationMsg("Package "+path, d))
+				} else if d := (deprecationFact{}); j.Facts.ImportPackageFact(imp.Types, &d) {
+					j.Errorf(node, "Package %s is deprecated: %s", path, d.Alternative)
 				}
 			}
 			return true
@@ -2958,6 +2923,218 @@ func (c *Checker) checkCalls(j *lint.Job, rules map[string]CallCheck) {
 	}
 }
 
+// CheckCallRules applies the call rules declared in the
+// configuration.
+func (c *Checker) CheckCallRules(j *lint.Job) {
+	if len(j.Pkg.Config.CallRules) == 0 {
+		return
+	}
+	rules := map[string]CallCheck{}
+	for _, r := range j.Pkg.Config.CallRules {
+		fn := configCallCheck(r)
+		if prev, ok := rules[r.Function]; ok {
+			rules[r.Function] = func(call *Call) {
+				prev(call)
+				fn(call)
+			}
+		} else {
+			rules[r.Function] = fn
+		}
+	}
+	c.checkCalls(j, rules)
+}
+
+func configCallCheck(r config.CallRule) CallCheck {
+	return func(call *Call) {
+		if r.Argument >= len(call.Args) {
+			return
+		}
+		arg := call.Args[r.Argument]
+		values := []Value{arg.Value}
+		if sig := call.Instr.Common().Signature(); sig.Variadic() && r.Argument == sig.Params().Len()-1 {
+			// The rule applies to the variadic arguments. We can't
+			// check those passed as an existing slice, as in f(s...).
+			elems, _ := variadicArgs(arg.Value.Value)
+			values = values[:0]
+			for _, elem := range elems {
+				values = append(values, Value{Value: elem})
+			}
+		}
+		for _, v := range values {
+			if msg := checkCallRule(r, v); msg != "" {
+				if r.Message != "" {
+					msg = r.Message
+				}
+				arg.Invalid(msg)
+				return
+			}
+		}
+	}
+}
+
+// CheckBannedAPIs reports imports of banned packages and uses of
+// banned objects, as declared in the configuration.
+func (c *Checker) CheckBannedAPIs(j *lint.Job) {
+	if len(j.Pkg.Config.BannedAPIs) == 0 || len(j.Pkg.GoFiles) == 0 {
+		return
+	}
+	dir := filepath.Dir(j.Pkg.GoFiles[0])
+	var banned []config.BannedAPI
+	for _, b := range j.Pkg.Config.BannedAPIs {
+		if !b.Allowed(dir) {
+			banned = append(banned, b)
+		}
+	}
+	if len(banned) == 0 {
+		return
+	}
+	report := func(node lint.Positioner, kind string, match func(name string) bool) {
+		for _, b := range banned {
+			if !match(b.Name) {
+				continue
+			}
+			if b.Message != "" {
+				j.Errorf(node, "%s of %s is banned: %s", kind, b.Name, b.Message)
+			} else {
+				j.Errorf(node, "%s of %s is banned", kind, b.Name)
+			}
+			return
+		}
+	}
+	is := func(s string) func(string) bool {
+		return func(name string) bool { return name == s }
+	}
+	// A package may use the APIs it defines.
+	external := func(obj types.Object) bool {
+		return obj != nil && obj.Pkg() != nil && obj.Pkg() != j.Pkg.Types
+	}
+
+	for _, f := range j.Pkg.Syntax {
+		for _, imp := range f.Imports {
+			path, err := strconv.Unquote(imp.Path.Value)
+			if err != nil {
+				continue
+			}
+			report(imp, "import", is(path))
+		}
+	}
+
+	// Functions that are called are reported as calls, all other
+	// uses, such as callbacks and method values, as uses.
+	callees := map[*ast.Ident]bool{}
+	fn := func(node ast.Node) {
+		call := node.(*ast.CallExpr)
+		name := CallNameAST(j, call)
+		if name == "" {
+			return
+		}
+		var id *ast.Ident
+		switch fun := astutil.Unparen(call.Fun).(type) {
+		case *ast.Ident:
+			id = fun
+		case *ast.SelectorExpr:
+			id = fun.Sel
+		}
+		callees[id] = true
+		if external(j.Pkg.TypesInfo.ObjectOf(id)) {
+			report(id, "call", is(name))
+		}
+	}
+	j.Pkg.Inspector.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, fn)
+
+	for id, obj := range j.Pkg.TypesInfo.Uses {
+		if callees[id] || !external(obj) {
+			continue
+		}
+		switch obj := obj.(type) {
+		case *types.Func:
+			report(id, "use", is(lint.FuncName(obj)))
+		case *types.Var:
+			if obj.IsField() || obj.Parent() != obj.Pkg().Scope() {
+				continue
+			}
+			report(id, "use", func(name string) bool { return IsObject(obj, name) })
+		case *types.TypeName, *types.Const:
+			report(id, "use", func(name string) bool { return IsObject(obj, name) })
+		}
+	}
+}
+
+// checkCallRule returns the message of r's violation by v, or the
+// empty string.
+func checkCallRule(r config.CallRule, v Value) string {
+	switch r.Rule {
+	case config.CallRuleConstant:
+		if len(extractConsts(v.Value)) == 0 {
+			return fmt.Sprintf("argument %d of %s must be a constant", r.Argument, r.Function)
+		}
+	case config.CallRuleNotNil:
+		for _, k := range extractConsts(v.Value) {
+			if k.IsNil() {
+				return fmt.Sprintf("argument %d of %s must not be nil", r.Argument, r.Function)
+			}
+		}
+	case config.CallRuleRegexp:
+		if err := ValidateRegexp(v); err != nil {
+			return err.Error()
+		}
+	case config.CallRuleURL:
+		if err := ValidateURL(v); err != nil {
+			return err.Error()
+		}
+	case config.CallRuleTimeLayout:
+		if err := ValidateTimeLayout(v); err != nil {
+			return err.Error()
+		}
+	case config.CallRuleHostPort:
+		if !ValidHostPort(v) {
+			return MsgInvalidHostPort
+		}
+	}
+	return ""
+}
+
+// variadicArgs returns the arguments that make up v, the slice the
+// SSA builder creates for the variadic arguments of calls such as
+// f(a, b). It returns false if v is any other slice, as in f(s...)
+// or f().
+func variadicArgs(v ssa.Value) ([]ssa.Value, bool) {
+	sl, ok := v.(*ssa.Slice)
+	if !ok {
+		return nil, false
+	}
+	alloc, ok := sl.X.(*ssa.Alloc)
+	if !ok {
+		return nil, false
+	}
+	arr, ok := Dereference(alloc.Type()).(*types.Array)
+	if !ok {
+		return nil, false
+	}
+	out := make([]ssa.Value, arr.Len())
+	for _, ref := range *alloc.Referrers() {
+		ia, ok := ref.(*ssa.IndexAddr)
+		if !ok {
+			continue
+		}
+		k, ok := ia.Index.(*ssa.Const)
+		if !ok {
+			return nil, false
+		}
+		for _, ref := range *ia.Referrers() {
+			if st, ok := ref.(*ssa.Store); ok && st.Addr == ia {
+				out[k.Int64()] = st.Val
+			}
+		}
+	}
+	for _, v := range out {
+		if v == nil {
+			return nil, false
+		}
+	}
+	return out, true
+}
+
 func shortCallName(call *ssa.CallCommon) string {
 	if call.IsInvoke() {
 		return ""
@@ -3419,7 +3596,8 @@ func (c *Checker) CheckUnreachableTypeCases(j *lint.Job) {
 		for i, cc := range ccs[:len(ccs)-1] {
 			for _, next := range ccs[i+1:] {
 				if T, V, yes := subsumesAny(cc.types, next.types); yes {
-					j.Errorf(next.cc, "unreachable case clause: %s will always match before %s", T.String(), V.String())
+					p := j.Errorf(next.cc, "unreachable case clause: %s will always match before %s", T.String(), V.String())
+					j.Related(p, cc.cc, "%s is matched here", T.String())
 				}
 			}
 		}
@@ -0,0 +1,24 @@
+package pkg
+
+import (
+	"io/ioutil" // MATCH "import of io/ioutil is banned: use io and os instead"
+	"net/http"
+	"strings"
+)
+
+func apply(fn func(string) string) {}
+
+func fn(req *http.Request) {
+	ioutil.ReadFile("")
+	c := http.DefaultClient // MATCH "use of net/http.DefaultClient is banned"
+	c.Do(req)               // MATCH "call of (*net/http.Client).Do is banned"
+	do := c.Do              // MATCH "use of (*net/http.Client).Do is banned"
+	do(req)
+	var sb strings.Builder // MATCH "use of strings.Builder is banned"
+	_ = sb
+	strings.Repeat("", 1)
+	apply(strings.ToUpper)   // MATCH "use of strings.ToUpper is banned"
+	upper := strings.ToUpper // MATCH "use of strings.ToUpper is banned"
+	(strings.ToUpper)("")    // MATCH "call of strings.ToUpper is banned"
+	_ = upper
+}
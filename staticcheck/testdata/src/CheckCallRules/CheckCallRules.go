@@ -0,0 +1,29 @@
+package pkg
+
+func Fields(msg string, kv map[string]string) {}
+
+func Dial(addr string) {}
+
+func Log(msg interface{}, kv ...interface{}) {}
+
+type Router struct{}
+
+func (*Router) Handle(pattern string) {}
+
+func fn(s string) {
+	Fields("msg", map[string]string{})
+	Fields(s, map[string]string{}) // MATCH /argument 0 of CheckCallRules.Fields must be a constant/
+	Fields("msg", nil)             // MATCH /argument 1 of CheckCallRules.Fields must not be nil/
+
+	var r Router
+	r.Handle(`^/users/\d+$`)
+	r.Handle(`^/users/(\d+$`) // MATCH /Handle requires a valid route pattern/
+
+	Log("msg", "key", 1)
+	Log(s)               // MATCH /argument 0 of CheckCallRules.Log must be a constant/
+	Log("msg", "key", s) // MATCH /argument 1 of CheckCallRules.Log must be a constant/
+	Log("msg", []interface{}{"key", 1}...)
+
+	Dial("localhost:8080")
+	Dial("localhost") // MATCH /invalid port or service name in host:port pair/
+}
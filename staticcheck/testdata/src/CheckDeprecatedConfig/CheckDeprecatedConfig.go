@@ -0,0 +1,14 @@
+package pkg
+
+import (
+	"bytes"
+	"container/list" // MATCH "Package container/list is deprecated: use a slice"
+	"strings"
+)
+
+func fn(buf *bytes.Buffer) {
+	strings.Title("")     // MATCH "strings.Title is deprecated: use golang.org/x/text/cases instead"
+	buf.Truncate(0)       // MATCH "buf.Truncate is deprecated"
+	strings.Repeat("", 1) // not yet deprecated
+	_ = list.New()
+}
@@ -0,0 +1,10 @@
+package pkg
+
+import "CheckDeprecatedFieldsassist"
+
+func fn(t CheckDeprecatedFieldsassist.T) {
+	_ = CheckDeprecatedFieldsassist.Config.Timeout // MATCH "Use Deadline."
+	_ = CheckDeprecatedFieldsassist.Config.Deadline
+	_ = t.Options[0].Old // MATCH "Use New."
+	_ = t.Options[0].New
+}
@@ -0,0 +1,15 @@
+package pkg
+
+var Config struct {
+	// Deprecated: Use Deadline.
+	Timeout  int
+	Deadline int
+}
+
+type T struct {
+	Options []struct {
+		// Deprecated: Use New.
+		Old int
+		New int
+	}
+}
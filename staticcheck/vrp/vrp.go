@@ -4,6 +4,7 @@ package vrp
 // it reusable.
 
 import (
+	"context"
 	"fmt"
 	"go/constant"
 	"go/token"
@@ -473,6 +474,23 @@ func BuildGraph(f *ssa.Function) *Graph {
 }
 
 func (g *Graph) Solve() Ranges {
+	r, _ := g.SolveContext(context.Background())
+	return r
+}
+
+// SolveContext is like Solve, but gives up and returns ctx.Err()
+// once ctx is done.
+func (g *Graph) SolveContext(ctx context.Context) (Ranges, error) {
+	done := ctx.Done()
+	canceled := func() bool {
+		select {
+		case <-done:
+			return true
+		default:
+			return false
+		}
+	}
+
 	var consts []Z
 	off := NewZ(1)
 	for _, n := range g.Vertices {
@@ -493,6 +511,9 @@ func (g *Graph) Solve() Ranges {
 	sort.Sort(Zs(consts))
 
 	for scc, vertices := range g.SCCs {
+		if canceled() {
+			return nil, ctx.Err()
+		}
 		n := 0
 		n = len(vertices)
 		if n == 1 {
@@ -528,6 +549,9 @@ func (g *Graph) Solve() Ranges {
 			uses := g.uses(scc)
 			entries := g.entries(scc)
 			for len(entries) > 0 {
+				if canceled() {
+					return nil, ctx.Err()
+				}
 				v := entries[len(entries)-1]
 				entries = entries[:len(entries)-1]
 				for _, use := range uses[v] {
@@ -556,6 +580,9 @@ func (g *Graph) Solve() Ranges {
 
 			actives := g.actives(scc)
 			for len(actives) > 0 {
+				if canceled() {
+					return nil, ctx.Err()
+				}
 				v := actives[len(actives)-1]
 				actives = actives[:len(actives)-1]
 				for _, use := range uses[v] {
@@ -615,7 +642,7 @@ func (g *Graph) Solve() Ranges {
 		g.ranges[v] = i
 	}
 
-	return g.ranges
+	return g.ranges, nil
 }
 
 func VertexString(v *Vertex) string {
@@ -1,6 +1,7 @@
 package unused
 
 import (
+	"context"
 	"fmt"
 	"go/ast"
 	"go/token"
@@ -142,6 +143,9 @@ type Checker struct {
 	seenMu sync.Mutex
 	seen   map[token.Position]struct{}
 	out    []types.Object
+	// abandoned records packages whose processing exceeded the job
+	// time budget.
+	abandoned map[*types.Package]bool
 }
 
 func (*Checker) Name() string   { return "unused" }
@@ -419,6 +423,7 @@ func (c *Checker) Init(prog *lint.Program) {
 	}
 	c.initialPackages = prog.InitialPackages
 	c.seen = map[token.Position]struct{}{}
+	c.abandoned = map[*types.Package]bool{}
 
 	c.scopes = map[*types.Scope]*ssa.Function{}
 	for _, pkg := range prog.InitialPackages {
@@ -435,8 +440,9 @@ func (c *Checker) Init(prog *lint.Program) {
 	// report results in the actual checker function.
 	var out []types.Object
 	if c.WholeProgram {
-		// (e1) all packages share a single graph
-		out = c.processPkgs(prog.InitialPackages...)
+		// (e1) all packages share a single graph. The job time
+		// budget is per package, so it doesn't apply to the graph.
+		out, _ = c.processPkgs(context.Background(), prog.InitialPackages...)
 	} else {
 		var wg sync.WaitGroup
 		var mu sync.Mutex
@@ -444,8 +450,17 @@ func (c *Checker) Init(prog *lint.Program) {
 			pkg := pkg
 			wg.Add(1)
 			go func() {
-				res := c.processPkgs(pkg)
+				ctx := context.Background()
+				if prog.JobTimeout > 0 {
+					var cancel context.CancelFunc
+					ctx, cancel = context.WithTimeout(ctx, prog.JobTimeout)
+					defer cancel()
+				}
+				res, err := c.processPkgs(ctx, pkg)
 				mu.Lock()
+				if err != nil {
+					c.abandoned[pkg.Types] = true
+				}
 				out = append(out, res...)
 				mu.Unlock()
 				wg.Done()
@@ -453,11 +468,31 @@ func (c *Checker) Init(prog *lint.Program) {
 		}
 		wg.Wait()
 	}
+
+	// Variants of a package, such as the package and its test
+	// variant, share files, and their graphs are merged by the
+	// positions of declarations: an object is only unused if it is
+	// unused in all variants, so that objects only used by tests
+	// aren't reported. If the processing of a variant was abandoned,
+	// we don't know whether its files' objects are used.
+	abandonedFiles := map[string]bool{}
+	for _, pkg := range prog.InitialPackages {
+		if !c.abandoned[pkg.Types] {
+			continue
+		}
+		for _, f := range pkg.Syntax {
+			abandonedFiles[prog.Fset().File(f.Pos()).Name()] = true
+		}
+	}
 	out2 := make([]types.Object, 0, len(out))
 	for _, v := range out {
-		if _, ok := c.seen[prog.Fset().Position(v.Pos())]; !ok {
-			out2 = append(out2, v)
+		if _, ok := c.seen[prog.Fset().Position(v.Pos())]; ok {
+			continue
 		}
+		if tf := prog.Fset().File(v.Pos()); tf != nil && abandonedFiles[tf.Name()] {
+			continue
+		}
+		out2 = append(out2, v)
 	}
 	c.out = out2
 }
@@ -465,6 +500,10 @@ func (c *Checker) Init(prog *lint.Program) {
 func (c *Checker) Lint(j *lint.Job) {
 	// The actual work is being done in Init. We only report existing
 	// results here.
+	if c.abandoned[j.Pkg.Types] {
+		j.Abandon()
+		return
+	}
 	unused := c.out
 	for _, u := range unused {
 		if u.Pkg() != j.Pkg.Types {
@@ -537,8 +576,11 @@ func (graph *Graph) quieten(node *Node) {
 	}
 }
 
-func (c *Checker) processPkgs(pkgs ...*lint.Pkg) []types.Object {
+// processPkgs returns the unused objects in pkgs. It gives up and
+// returns ctx.Err() once ctx is done.
+func (c *Checker) processPkgs(ctx context.Context, pkgs ...*lint.Pkg) ([]types.Object, error) {
 	graph := NewGraph()
+	graph.done = ctx.Done()
 	graph.wholeProgram = c.WholeProgram
 	graph.scopes = c.scopes
 	graph.initialPackages = c.initialPackages
@@ -574,6 +616,9 @@ func (c *Checker) processPkgs(pkgs ...*lint.Pkg) []types.Object {
 		// (8.0) handle interfaces
 		// (e2) types aim to implement all exported interfaces from all packages
 		for _, t := range notIfaces {
+			if graph.canceled() {
+				return nil, ctx.Err()
+			}
 			ms := graph.msCache.MethodSet(t)
 			for _, iface := range ifaces {
 				if sels, ok := graph.implements(t, iface, ms); ok {
@@ -611,6 +656,9 @@ func (c *Checker) processPkgs(pkgs ...*lint.Pkg) []types.Object {
 	}
 
 	graph.color(graph.Root)
+	if graph.canceled() {
+		return nil, ctx.Err()
+	}
 	// if a node is unused, don't report any of the node's
 	// children as unused. for example, if a function is unused,
 	// don't flag its receiver. if a named type is unused, don't
@@ -710,7 +758,7 @@ func (c *Checker) processPkgs(pkgs ...*lint.Pkg) []types.Object {
 		report(value.(*Node))
 	})
 
-	return out
+	return out, nil
 }
 
 type Graph struct {
@@ -730,6 +778,19 @@ type Graph struct {
 	seenFns   map[*ssa.Function]struct{}
 
 	initialPackages []*lint.Pkg
+
+	// done, if not nil, is closed when the graph should stop being
+	// processed.
+	done <-chan struct{}
+}
+
+func (g *Graph) canceled() bool {
+	select {
+	case <-g.done:
+		return true
+	default:
+		return false
+	}
 }
 
 func NewGraph() *Graph {
@@ -742,7 +803,7 @@ func NewGraph() *Graph {
 }
 
 func (g *Graph) color(root *Node) {
-	if root.seen {
+	if root.seen || g.canceled() {
 		return
 	}
 	root.seen = true
@@ -1038,6 +1099,9 @@ func (g *Graph) entry(pkg *lint.Pkg) {
 	// Find constants being used inside functions, find sinks in tests
 	handledConsts := map[*ast.Ident]struct{}{}
 	for _, fn := range pkg.InitialFunctions {
+		if g.canceled() {
+			return
+		}
 		g.see(fn)
 		node := fn.Syntax()
 		if node == nil {